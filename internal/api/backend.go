@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wfh/lsc/internal/config"
+)
+
+// AgentBackend abstracts the operations needed to drive a chat session
+// against an agent server. Client dispatches to a concrete implementation
+// chosen by config.Config.Backend, so the UI/streaming code never needs to
+// know whether it's talking to a LangGraph deployment or something else.
+type AgentBackend interface {
+	CreateThread(ctx context.Context, opts ...RequestOption) (*Thread, error)
+	SearchThreads(ctx context.Context, limit, offset int) ([]Thread, error)
+	GetThread(ctx context.Context, threadID string, selectFields ...string) (*Thread, error)
+	GetThreadState(ctx context.Context, threadID string) (*ThreadState, error)
+	StreamRun(ctx context.Context, threadID string, assistantID string, userMessage string, onToken TokenCallback, onToolCall ToolCallCallback, opts ...RequestOption) error
+	UpdateThreadState(ctx context.Context, threadID string, values map[string]interface{}) (*ThreadState, error)
+	// DeleteThread permanently removes a thread. Both backends support this:
+	// the LangSmith backend deletes it server-side, the OpenAI backend removes
+	// its local state file.
+	DeleteThread(ctx context.Context, threadID string) error
+	// UpdateThreadMetadata merges metadata into a thread's existing metadata
+	// (used to record a user-supplied title from the thread list's rename).
+	UpdateThreadMetadata(ctx context.Context, threadID string, metadata map[string]interface{}) (*Thread, error)
+}
+
+// Client is the API client used by cmd and ui code. It delegates every
+// operation to the AgentBackend selected by config.Config.Backend.
+type Client struct {
+	backend AgentBackend
+}
+
+// clientOptions holds the resolved settings applied by ClientOption funcs.
+type clientOptions struct {
+	retryPolicy config.RetryPolicy
+}
+
+// ClientOption customizes a Client constructed by NewClient.
+type ClientOption func(*clientOptions)
+
+// WithRetryPolicy overrides the retry policy from config.Config.RetryPolicy
+// (or DefaultRetryPolicy if that's unset) for this client.
+func WithRetryPolicy(policy config.RetryPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.retryPolicy = policy
+	}
+}
+
+func resolveClientOptions(cfg *config.Config, opts []ClientOption) clientOptions {
+	o := clientOptions{retryPolicy: config.DefaultRetryPolicy()}
+	if cfg.RetryPolicy != nil {
+		o.retryPolicy = *cfg.RetryPolicy
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// NewClient creates a new API client from config, dispatching on cfg.Backend.
+func NewClient(cfg *config.Config, opts ...ClientOption) *Client {
+	var backend AgentBackend
+	switch cfg.Backend {
+	case config.BackendOpenAI:
+		backend = newOpenAIBackend(cfg)
+	default:
+		backend = newLangSmithBackend(cfg, opts...)
+	}
+	return &Client{backend: backend}
+}
+
+// CreateThread creates a new thread
+func (c *Client) CreateThread(ctx context.Context, opts ...RequestOption) (*Thread, error) {
+	return c.backend.CreateThread(ctx, opts...)
+}
+
+// SearchThreads searches for existing threads, offset by the given number of
+// results for incremental pagination.
+func (c *Client) SearchThreads(ctx context.Context, limit, offset int) ([]Thread, error) {
+	return c.backend.SearchThreads(ctx, limit, offset)
+}
+
+// GetThread gets a thread with optional field selection
+func (c *Client) GetThread(ctx context.Context, threadID string, selectFields ...string) (*Thread, error) {
+	return c.backend.GetThread(ctx, threadID, selectFields...)
+}
+
+// GetThreadState gets the current state of a thread
+func (c *Client) GetThreadState(ctx context.Context, threadID string) (*ThreadState, error) {
+	return c.backend.GetThreadState(ctx, threadID)
+}
+
+// StreamRun creates a streaming run and calls onToken for each text token and
+// onToolCall for each update to a streaming tool call. onToolCall may be nil
+// if the caller doesn't care about tool calls. If the run pauses on a
+// LangGraph interrupt(), StreamRun returns an *InterruptError.
+func (c *Client) StreamRun(ctx context.Context, threadID string, assistantID string, userMessage string, onToken TokenCallback, onToolCall ToolCallCallback, opts ...RequestOption) error {
+	return c.backend.StreamRun(ctx, threadID, assistantID, userMessage, onToken, onToolCall, opts...)
+}
+
+// ResumeRun submits a human decision for a run paused on an interrupt(),
+// resuming it where the graph called interrupt(). It returns an error if the
+// active backend doesn't support interrupts (only the LangSmith backend
+// does, since other backends never pause mid-run).
+func (c *Client) ResumeRun(ctx context.Context, threadID, runID string, decision interface{}) error {
+	ls, ok := c.backend.(*langSmithBackend)
+	if !ok {
+		return fmt.Errorf("backend %T does not support resuming interrupted runs", c.backend)
+	}
+	return ls.ResumeRun(ctx, threadID, runID, decision)
+}
+
+// UpdateThreadState overwrites a thread's state values, e.g. to truncate its
+// message history to a prior checkpoint (/rewind) or seed a forked thread
+// (/fork).
+func (c *Client) UpdateThreadState(ctx context.Context, threadID string, values map[string]interface{}) (*ThreadState, error) {
+	return c.backend.UpdateThreadState(ctx, threadID, values)
+}
+
+// DeleteThread permanently removes a thread.
+func (c *Client) DeleteThread(ctx context.Context, threadID string) error {
+	return c.backend.DeleteThread(ctx, threadID)
+}
+
+// UpdateThreadMetadata merges metadata into a thread's existing metadata,
+// e.g. to record a user-supplied title from the thread list's rename.
+func (c *Client) UpdateThreadMetadata(ctx context.Context, threadID string, metadata map[string]interface{}) (*Thread, error) {
+	return c.backend.UpdateThreadMetadata(ctx, threadID, metadata)
+}
+
+// WithTransport returns a shallow copy of the client that streams using the
+// given transport. Only the LangSmith backend supports transport selection;
+// on other backends it's a no-op.
+func (c *Client) WithTransport(t Transport) *Client {
+	ls, ok := c.backend.(*langSmithBackend)
+	if !ok {
+		return c
+	}
+	return &Client{backend: ls.withTransport(t)}
+}
+
+// SetStreamDeadline aborts any in-flight (or future) StreamRun call at t,
+// returning context.DeadlineExceeded instead of streaming further tokens. A
+// zero Time clears the deadline; a past Time cancels immediately. Only the
+// LangSmith backend supports deadlines; on other backends it's a no-op.
+func (c *Client) SetStreamDeadline(t time.Time) {
+	ls, ok := c.backend.(*langSmithBackend)
+	if !ok {
+		return
+	}
+	ls.SetStreamDeadline(t)
+}
+
+// StreamRunWS streams a run over a WebSocket connection. It returns an error
+// if the active backend doesn't support the LangSmith WebSocket transport.
+func (c *Client) StreamRunWS(ctx context.Context, threadID string, assistantID string, userMessage string, onToken WSCallback) error {
+	ls, ok := c.backend.(*langSmithBackend)
+	if !ok {
+		return fmt.Errorf("backend %T does not support the WebSocket transport", c.backend)
+	}
+	return ls.StreamRunWS(ctx, threadID, assistantID, userMessage, onToken)
+}
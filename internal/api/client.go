@@ -9,40 +9,84 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
 
 	"github.com/wfh/lsc/internal/config"
 )
 
-// Client is the LangSmith Agent Server API client
-type Client struct {
-	endpoint   string
-	headers    map[string]string
-	httpClient *http.Client
+// Transport selects which wire protocol StreamRun uses to deliver tokens.
+type Transport int
+
+const (
+	// TransportSSE streams over a chunked text/event-stream response (the default).
+	TransportSSE Transport = iota
+	// TransportWS streams over a WebSocket connection, for proxies that mangle SSE.
+	TransportWS
+	// TransportAuto tries TransportWS first and falls back to TransportSSE if the
+	// WebSocket handshake fails.
+	TransportAuto
+)
+
+// langSmithBackend is the AgentBackend implementation that talks to a
+// LangSmith Agent Server deployment.
+type langSmithBackend struct {
+	endpoint    string
+	headers     map[string]string
+	httpClient  *http.Client
+	transport   Transport
+	retryPolicy config.RetryPolicy
+	deadlines   *Deadlines
 }
 
-// NewClient creates a new API client from config
-func NewClient(cfg *config.Config) *Client {
-	return &Client{
-		endpoint:   strings.TrimSuffix(cfg.Endpoint, "/"),
-		headers:    cfg.GetHeaders(),
-		httpClient: &http.Client{},
+// newLangSmithBackend creates a backend for a LangSmith Agent Server deployment.
+func newLangSmithBackend(cfg *config.Config, opts ...ClientOption) *langSmithBackend {
+	o := resolveClientOptions(cfg, opts)
+	return &langSmithBackend{
+		endpoint:    strings.TrimSuffix(cfg.Endpoint, "/"),
+		headers:     cfg.GetHeaders(),
+		httpClient:  &http.Client{},
+		transport:   TransportSSE,
+		retryPolicy: o.retryPolicy,
+		deadlines:   newDeadlines(),
 	}
 }
 
-// CreateThread creates a new thread
-func (c *Client) CreateThread(ctx context.Context) (*Thread, error) {
-	url := fmt.Sprintf("%s/threads", c.endpoint)
+// SetStreamDeadline aborts any in-flight (or future) StreamRun call at t,
+// without the caller having to thread a fresh context.Context through every
+// call. A zero Time clears the deadline; a past Time cancels immediately.
+func (c *langSmithBackend) SetStreamDeadline(t time.Time) {
+	c.deadlines.set(t)
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader([]byte("{}")))
-	if err != nil {
-		return nil, err
-	}
+// withTransport returns a shallow copy of the backend that streams using the
+// given transport, leaving the receiver untouched.
+func (c *langSmithBackend) withTransport(t Transport) *langSmithBackend {
+	clone := *c
+	clone.transport = t
+	return &clone
+}
 
-	for k, v := range c.headers {
-		req.Header.Set(k, v)
-	}
+// CreateThread creates a new thread. The request carries an Idempotency-Key
+// header (generated unless WithIdempotencyKey is passed) so a retried or
+// reissued call doesn't create a duplicate thread server-side; the key is
+// persisted to ~/.lsc/idempotency.json for replay via --retry-last.
+func (c *langSmithBackend) CreateThread(ctx context.Context, opts ...RequestOption) (*Thread, error) {
+	o := resolveRequestOptions(opts)
+	url := fmt.Sprintf("%s/threads", c.endpoint)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := doWithRetry(ctx, c.httpClient, c.retryPolicy, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader([]byte("{}")))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range c.headers {
+			req.Header.Set(k, v)
+		}
+		req.Header.Set("Idempotency-Key", o.idempotencyKey)
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -62,31 +106,37 @@ func (c *Client) CreateThread(ctx context.Context) (*Thread, error) {
 		return nil, err
 	}
 
+	if err := saveIdempotencyRecord("create_thread", IdempotencyRecord{Key: o.idempotencyKey, ThreadID: thread.ThreadID}); err != nil {
+		log.Printf("error persisting idempotency key: %v", err)
+	}
+
 	return &thread, nil
 }
 
-// SearchThreads searches for existing threads
-func (c *Client) SearchThreads(ctx context.Context, limit int) ([]Thread, error) {
+// SearchThreads searches for existing threads, offset by the given number of
+// results for incremental pagination.
+func (c *langSmithBackend) SearchThreads(ctx context.Context, limit, offset int) ([]Thread, error) {
 	url := fmt.Sprintf("%s/threads/search", c.endpoint)
 
 	body := map[string]interface{}{
-		"limit": limit,
+		"limit":  limit,
+		"offset": offset,
 	}
 	bodyBytes, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
-	if err != nil {
-		return nil, err
-	}
-
-	for k, v := range c.headers {
-		req.Header.Set(k, v)
-	}
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := doWithRetry(ctx, c.httpClient, c.retryPolicy, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range c.headers {
+			req.Header.Set(k, v)
+		}
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -110,19 +160,61 @@ func (c *Client) SearchThreads(ctx context.Context, limit int) ([]Thread, error)
 }
 
 // GetThreadState gets the current state of a thread
-func (c *Client) GetThreadState(ctx context.Context, threadID string) (*ThreadState, error) {
+func (c *langSmithBackend) GetThreadState(ctx context.Context, threadID string) (*ThreadState, error) {
 	url := fmt.Sprintf("%s/threads/%s/state", c.endpoint, threadID)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, err := doWithRetry(ctx, c.httpClient, c.retryPolicy, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range c.headers {
+			req.Header.Set(k, v)
+		}
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("error closing response body: %v", err)
+		}
+	}()
 
-	for k, v := range c.headers {
-		req.Header.Set(k, v)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get thread state: %s - %s", resp.Status, string(body))
+	}
+
+	var state ThreadState
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// UpdateThreadState overwrites a thread's state values, used to truncate
+// history for /rewind or seed a forked thread for /fork.
+func (c *langSmithBackend) UpdateThreadState(ctx context.Context, threadID string, values map[string]interface{}) (*ThreadState, error) {
+	url := fmt.Sprintf("%s/threads/%s/state", c.endpoint, threadID)
+
+	bodyBytes, err := json.Marshal(map[string]interface{}{"values": values})
+	if err != nil {
+		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := doWithRetry(ctx, c.httpClient, c.retryPolicy, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range c.headers {
+			req.Header.Set(k, v)
+		}
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +226,7 @@ func (c *Client) GetThreadState(ctx context.Context, threadID string) (*ThreadSt
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get thread state: %s - %s", resp.Status, string(body))
+		return nil, fmt.Errorf("failed to update thread state: %s - %s", resp.Status, string(body))
 	}
 
 	var state ThreadState
@@ -146,22 +238,95 @@ func (c *Client) GetThreadState(ctx context.Context, threadID string) (*ThreadSt
 }
 
 // GetThread gets a thread with optional field selection
-func (c *Client) GetThread(ctx context.Context, threadID string, selectFields ...string) (*Thread, error) {
+func (c *langSmithBackend) GetThread(ctx context.Context, threadID string, selectFields ...string) (*Thread, error) {
 	url := fmt.Sprintf("%s/threads/%s", c.endpoint, threadID)
 	if len(selectFields) > 0 {
 		url += "?select=" + strings.Join(selectFields, ",")
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, err := doWithRetry(ctx, c.httpClient, c.retryPolicy, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range c.headers {
+			req.Header.Set(k, v)
+		}
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("error closing response body: %v", err)
+		}
+	}()
 
-	for k, v := range c.headers {
-		req.Header.Set(k, v)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get thread: %s - %s", resp.Status, string(body))
+	}
+
+	var thread Thread
+	if err := json.NewDecoder(resp.Body).Decode(&thread); err != nil {
+		return nil, err
+	}
+
+	return &thread, nil
+}
+
+// DeleteThread permanently deletes a thread server-side.
+func (c *langSmithBackend) DeleteThread(ctx context.Context, threadID string) error {
+	url := fmt.Sprintf("%s/threads/%s", c.endpoint, threadID)
+
+	resp, err := doWithRetry(ctx, c.httpClient, c.retryPolicy, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range c.headers {
+			req.Header.Set(k, v)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete thread: %s - %s", resp.Status, string(body))
+	}
+
+	return nil
+}
+
+// UpdateThreadMetadata merges metadata into a thread's existing metadata,
+// e.g. to record a user-supplied title from the thread list's rename.
+func (c *langSmithBackend) UpdateThreadMetadata(ctx context.Context, threadID string, metadata map[string]interface{}) (*Thread, error) {
+	url := fmt.Sprintf("%s/threads/%s", c.endpoint, threadID)
+
+	bodyBytes, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+	if err != nil {
+		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := doWithRetry(ctx, c.httpClient, c.retryPolicy, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewReader(bodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range c.headers {
+			req.Header.Set(k, v)
+		}
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -173,7 +338,7 @@ func (c *Client) GetThread(ctx context.Context, threadID string, selectFields ..
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get thread: %s - %s", resp.Status, string(body))
+		return nil, fmt.Errorf("failed to update thread metadata: %s - %s", resp.Status, string(body))
 	}
 
 	var thread Thread
@@ -187,27 +352,78 @@ func (c *Client) GetThread(ctx context.Context, threadID string, selectFields ..
 // TokenCallback is called for each token received
 type TokenCallback func(token string)
 
-// StreamRun creates a streaming run and calls the callback for each token
-func (c *Client) StreamRun(ctx context.Context, threadID string, assistantID string, userMessage string, onToken TokenCallback) error {
+// WSCallback is called for each token received over the WebSocket transport.
+type WSCallback func(token string)
+
+// StreamRun creates a streaming run and calls onToken for each text token and
+// onToolCall (which may be nil) for each update to a streaming tool call. The
+// wire protocol used depends on the client's transport: TransportWS streams
+// over a WebSocket connection, TransportAuto tries WS and falls back to SSE
+// on handshake failure, and TransportSSE (the default) streams over
+// text/event-stream. Only the SSE transport currently surfaces tool calls
+// and interrupts; WS callers only receive text tokens.
+func (c *langSmithBackend) StreamRun(ctx context.Context, threadID string, assistantID string, userMessage string, onToken TokenCallback, onToolCall ToolCallCallback, opts ...RequestOption) error {
+	switch c.transport {
+	case TransportWS:
+		return c.StreamRunWS(ctx, threadID, assistantID, userMessage, WSCallback(onToken))
+	case TransportAuto:
+		err := c.StreamRunWS(ctx, threadID, assistantID, userMessage, WSCallback(onToken))
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(*wsHandshakeError); ok {
+			return c.streamRunSSE(ctx, threadID, assistantID, userMessage, onToken, onToolCall, opts...)
+		}
+		return err
+	default:
+		return c.streamRunSSE(ctx, threadID, assistantID, userMessage, onToken, onToolCall, opts...)
+	}
+}
+
+// streamRunSSE is the original text/event-stream implementation of StreamRun.
+// Like CreateThread, it attaches an Idempotency-Key header and persists the
+// key (plus enough context to resubmit) so --retry-last can replay the run
+// against the same thread instead of creating a duplicate.
+func (c *langSmithBackend) streamRunSSE(ctx context.Context, threadID string, assistantID string, userMessage string, onToken TokenCallback, onToolCall ToolCallCallback, opts ...RequestOption) error {
+	o := resolveRequestOptions(opts)
 	url := fmt.Sprintf("%s/threads/%s/runs/stream", c.endpoint, threadID)
 
 	runReq := NewRunRequest(assistantID, userMessage)
-	bodyBytes, err := json.Marshal(runReq)
-	if err != nil {
-		return err
+	if o.ragContext != "" {
+		runReq.Input["rag_context"] = o.ragContext
 	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
+	if len(o.tools) > 0 {
+		runReq.Input["tools"] = o.tools
+	}
+	bodyBytes, err := json.Marshal(runReq)
 	if err != nil {
 		return err
 	}
 
-	for k, v := range c.headers {
-		req.Header.Set(k, v)
+	if err := saveIdempotencyRecord("stream_run", IdempotencyRecord{
+		Key:         o.idempotencyKey,
+		ThreadID:    threadID,
+		AssistantID: assistantID,
+		UserMessage: userMessage,
+	}); err != nil {
+		log.Printf("error persisting idempotency key: %v", err)
 	}
-	req.Header.Set("Accept", "text/event-stream")
 
-	resp, err := c.httpClient.Do(req)
+	// Retries only apply to establishing the stream: once ParseSSE below
+	// starts handing tokens to onToken, a failure must surface as an error
+	// rather than silently retrying and double-rendering tokens.
+	resp, err := doWithRetry(ctx, c.httpClient, c.retryPolicy, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range c.headers {
+			req.Header.Set(k, v)
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Idempotency-Key", o.idempotencyKey)
+		return req, nil
+	})
 	if err != nil {
 		return err
 	}
@@ -222,26 +438,161 @@ func (c *Client) StreamRun(ctx context.Context, threadID string, assistantID str
 		return fmt.Errorf("failed to create run: %s - %s", resp.Status, string(body))
 	}
 
-	// Parse SSE stream
-	return ParseSSE(resp.Body, func(event SSEEvent) {
-		if IsEndEvent(event) {
+	// Parse SSE stream in the background so a stream deadline (set via
+	// SetStreamDeadline) can preempt it: the deadline's cancelCh is selected
+	// against the parser's completion below, rather than threaded into
+	// ParseSSE itself, so a fired deadline can't land mid-event.
+	done := make(chan error, 1)
+	go func() {
+		accumulator := newToolCallAccumulator()
+		var interrupted *InterruptInfo
+		parseErr := ParseSSE(resp.Body, func(event SSEEvent) bool {
+			if IsInterruptEvent(event) {
+				var info InterruptInfo
+				if err := json.Unmarshal([]byte(event.Data), &info); err == nil {
+					interrupted = &info
+				}
+				return false
+			}
+
+			if IsEndEvent(event) {
+				return true
+			}
+
+			if !IsMessageEvent(event) && event.Event != "" {
+				return true
+			}
+
+			// Parse message chunk
+			chunk, err := ParseMessageChunk(event.Data)
+			if err != nil || chunk == nil {
+				return true
+			}
+
+			// Only emit content and tool calls from AI message chunks
+			if chunk.Type != "AIMessageChunk" && chunk.Type != "ai" {
+				return true
+			}
+
+			if content := chunk.GetContent(); content != "" {
+				onToken(content)
+			}
+
+			if onToolCall != nil {
+				for _, tc := range chunk.ToolCallChunks {
+					onToolCall(accumulator.apply(tc))
+				}
+			}
+
+			return true
+		})
+		if parseErr != nil {
+			done <- parseErr
 			return
 		}
-
-		if !IsMessageEvent(event) && event.Event != "" {
+		if interrupted != nil {
+			done <- &InterruptError{ThreadID: threadID, Info: *interrupted}
 			return
 		}
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-c.deadlines.done():
+		// Closing the body unblocks the scanner's in-flight Read so the
+		// goroutine above can finish flushing whatever event it already has
+		// buffered before we report the deadline.
+		_ = resp.Body.Close()
+		<-done
+		return context.DeadlineExceeded
+	}
+}
+
+// wsHandshakeError wraps a failure to establish the WebSocket connection,
+// distinct from an error mid-stream, so TransportAuto knows it's safe to
+// fall back to SSE.
+type wsHandshakeError struct {
+	err error
+}
+
+func (e *wsHandshakeError) Error() string { return e.err.Error() }
+func (e *wsHandshakeError) Unwrap() error { return e.err }
+
+// wsEnvelope mirrors the shape of an SSE event when framed over WebSocket:
+// either {"event":..,"data":..} or a bare MessageChunk JSON object.
+type wsEnvelope struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// StreamRunWS creates a streaming run and calls the callback for each token,
+// using a WebSocket connection instead of SSE. This is useful for clients
+// behind proxies that mangle chunked text/event-stream responses.
+func (c *langSmithBackend) StreamRunWS(ctx context.Context, threadID string, assistantID string, userMessage string, onToken WSCallback) error {
+	wsURL := strings.Replace(c.endpoint, "https://", "wss://", 1)
+	wsURL = strings.Replace(wsURL, "http://", "ws://", 1)
+	wsURL = fmt.Sprintf("%s/threads/%s/runs/stream/ws", wsURL, threadID)
+
+	header := http.Header{}
+	for k, v := range c.headers {
+		header.Set(k, v)
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		return &wsHandshakeError{err: err}
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Printf("error closing websocket connection: %v", err)
+		}
+	}()
+
+	runReq := NewRunRequest(assistantID, userMessage)
+	bodyBytes, err := json.Marshal(runReq)
+	if err != nil {
+		return err
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, bodyBytes); err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	for {
+		_, frame, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
 
-		// Parse message chunk
-		chunk, err := ParseMessageChunk(event.Data)
+		data := frame
+		var env wsEnvelope
+		if err := json.Unmarshal(frame, &env); err == nil && len(env.Data) > 0 {
+			if env.Event == "end" || env.Event == "done" {
+				continue
+			}
+			data = env.Data
+		}
+
+		chunk, err := ParseMessageChunk(string(data))
 		if err != nil || chunk == nil {
-			return
+			continue
 		}
 
-		// Only emit content from AI message chunks
 		content := chunk.GetContent()
 		if content != "" && (chunk.Type == "AIMessageChunk" || chunk.Type == "ai") {
 			onToken(content)
 		}
-	})
+	}
 }
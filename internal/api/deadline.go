@@ -0,0 +1,72 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// Deadlines tracks a single mutex-guarded deadline for an in-flight
+// StreamRun, modeled on the cancelCh pattern net.Pipe uses for its read and
+// write deadlines: cancelCh is closed when the timer fires (or the deadline
+// is already in the past) and reallocated the next time the deadline is
+// reset to a future time or cleared.
+type Deadlines struct {
+	mu       sync.Mutex
+	timer    *time.Timer
+	cancelCh chan struct{}
+}
+
+// newDeadlines returns a Deadlines with no deadline set.
+func newDeadlines() *Deadlines {
+	return &Deadlines{cancelCh: make(chan struct{})}
+}
+
+func isClosedChan(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// set updates the deadline. A zero Time clears it; a Time already in the
+// past cancels immediately.
+func (d *Deadlines) set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil && !d.timer.Stop() {
+		<-d.cancelCh // the timer already fired; wait for it to close cancelCh
+	}
+	d.timer = nil
+
+	closed := isClosedChan(d.cancelCh)
+	if t.IsZero() {
+		if closed {
+			d.cancelCh = make(chan struct{})
+		}
+		return
+	}
+
+	if dur := time.Until(t); dur > 0 {
+		if closed {
+			d.cancelCh = make(chan struct{})
+		}
+		cancelCh := d.cancelCh
+		d.timer = time.AfterFunc(dur, func() { close(cancelCh) })
+		return
+	}
+
+	if !closed {
+		close(d.cancelCh)
+	}
+}
+
+// done returns the channel that closes once the current deadline elapses. If
+// no deadline is set, the channel never closes.
+func (d *Deadlines) done() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cancelCh
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wfh/lsc/internal/config"
+)
+
+// RequestOption customizes a single CreateThread or StreamRun call.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	idempotencyKey string
+	ragContext     string
+	tools          []string
+}
+
+// WithIdempotencyKey attaches an Idempotency-Key header to the request. If
+// never set (or set to ""), CreateThread and StreamRun generate one.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// WithRAGContext attaches content (the concatenated text of an agent's RAG
+// files) to a StreamRun call as additional retrieval context.
+func WithRAGContext(content string) RequestOption {
+	return func(o *requestOptions) {
+		o.ragContext = content
+	}
+}
+
+// WithToolAllowList restricts a StreamRun call to the given tool names,
+// overriding whichever tools the assistant is configured with by default.
+func WithToolAllowList(tools []string) RequestOption {
+	return func(o *requestOptions) {
+		o.tools = tools
+	}
+}
+
+func resolveRequestOptions(opts []RequestOption) requestOptions {
+	var o requestOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.idempotencyKey == "" {
+		o.idempotencyKey = newIdempotencyKey()
+	}
+	return o
+}
+
+// newIdempotencyKey generates a random UUIDv4.
+func newIdempotencyKey() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand failing is effectively unrecoverable; fall back to a
+		// timestamp-based key rather than panicking mid-request.
+		return fmt.Sprintf("fallback-%d", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// IdempotencyRecord is the last-used idempotency key for one logical
+// operation, persisted so a crashed or interrupted CLI invocation can be
+// reissued against the same server-side deduplication key.
+type IdempotencyRecord struct {
+	Key         string `json:"key"`
+	ThreadID    string `json:"thread_id,omitempty"`
+	AssistantID string `json:"assistant_id,omitempty"`
+	UserMessage string `json:"user_message,omitempty"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// idempotencyPath returns ~/.lsc/idempotency.json.
+func idempotencyPath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "idempotency.json"), nil
+}
+
+// LoadIdempotencyStore reads the persisted per-operation idempotency
+// records, keyed by logical operation name ("create_thread", "stream_run").
+// A missing file is not an error; it returns an empty store.
+func LoadIdempotencyStore() (map[string]IdempotencyRecord, error) {
+	path, err := idempotencyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]IdempotencyRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var store map[string]IdempotencyRecord
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, err
+	}
+	if store == nil {
+		store = map[string]IdempotencyRecord{}
+	}
+	return store, nil
+}
+
+// saveIdempotencyRecord persists the last-used key for a logical operation.
+func saveIdempotencyRecord(op string, rec IdempotencyRecord) error {
+	store, err := LoadIdempotencyStore()
+	if err != nil {
+		return err
+	}
+
+	rec.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	store[op] = rec
+
+	path, err := idempotencyPath()
+	if err != nil {
+		return err
+	}
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// LastRun returns the idempotency record for the most recent StreamRun call,
+// used by --retry-last to replay it without creating a duplicate run.
+func LastRun() (IdempotencyRecord, bool, error) {
+	store, err := LoadIdempotencyStore()
+	if err != nil {
+		return IdempotencyRecord{}, false, err
+	}
+	rec, ok := store["stream_run"]
+	return rec, ok, nil
+}
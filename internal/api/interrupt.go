@@ -0,0 +1,68 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+)
+
+// InterruptInfo describes a paused run surfaced by the server's "interrupt"
+// SSE event, emitted when the graph calls LangGraph's interrupt().
+type InterruptInfo struct {
+	RunID string      `json:"run_id"`
+	Value interface{} `json:"value"`
+}
+
+// InterruptError is returned by StreamRun when the run pauses on an
+// interrupt(). The caller should present Info.Value to the user and, once a
+// decision is made, resume the run with Client.ResumeRun.
+type InterruptError struct {
+	ThreadID string
+	Info     InterruptInfo
+}
+
+func (e *InterruptError) Error() string {
+	return fmt.Sprintf("run %s paused for approval: %v", e.Info.RunID, e.Info.Value)
+}
+
+// ResumeRun submits a human decision for a run paused on an interrupt(),
+// resuming it at the point the graph called interrupt(). decision is
+// typically a bool (approve/reject) but the server accepts any JSON value
+// the interrupted node expects back.
+func (c *langSmithBackend) ResumeRun(ctx context.Context, threadID, runID string, decision interface{}) error {
+	url := fmt.Sprintf("%s/threads/%s/runs/%s/resume", c.endpoint, threadID, runID)
+
+	bodyBytes, err := json.Marshal(map[string]interface{}{"decision": decision})
+	if err != nil {
+		return err
+	}
+
+	resp, err := doWithRetry(ctx, c.httpClient, c.retryPolicy, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range c.headers {
+			req.Header.Set(k, v)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to resume run: %s - %s", resp.Status, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wfh/lsc/internal/config"
+)
+
+// openAIBackend talks to an OpenAI-compatible /v1/chat/completions endpoint.
+// Such servers are stateless, so threads and their message history are
+// synthesized and persisted locally under ~/.lsc/threads/.
+type openAIBackend struct {
+	endpoint     string
+	headers      map[string]string
+	httpClient   *http.Client
+	model        string
+	systemPrompt string
+}
+
+func newOpenAIBackend(cfg *config.Config) *openAIBackend {
+	b := &openAIBackend{
+		endpoint:   strings.TrimSuffix(cfg.Endpoint, "/"),
+		headers:    cfg.GetHeaders(),
+		httpClient: &http.Client{},
+		model:      "gpt-4o-mini",
+	}
+	if cfg.OpenAI != nil {
+		if cfg.OpenAI.Model != "" {
+			b.model = cfg.OpenAI.Model
+		}
+		b.systemPrompt = cfg.OpenAI.SystemPrompt
+	}
+	return b
+}
+
+// openAIThreadsDir returns ~/.lsc/threads/, creating it if necessary.
+func openAIThreadsDir() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "threads")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func (b *openAIBackend) threadPath(threadID string) (string, error) {
+	dir, err := openAIThreadsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, threadID+".json"), nil
+}
+
+func (b *openAIBackend) loadThread(threadID string) (*Thread, error) {
+	path, err := b.threadPath(threadID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var thread Thread
+	if err := json.Unmarshal(data, &thread); err != nil {
+		return nil, err
+	}
+	return &thread, nil
+}
+
+func (b *openAIBackend) saveThread(thread *Thread) error {
+	path, err := b.threadPath(thread.ThreadID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(thread, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// CreateThread synthesizes a new local thread.
+func (b *openAIBackend) CreateThread(ctx context.Context, opts ...RequestOption) (*Thread, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	thread := &Thread{
+		ThreadID:  fmt.Sprintf("local-%d", time.Now().UnixNano()),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Values: map[string]interface{}{
+			"messages": []interface{}{},
+		},
+	}
+	if err := b.saveThread(thread); err != nil {
+		return nil, fmt.Errorf("failed to persist thread: %w", err)
+	}
+	return thread, nil
+}
+
+// SearchThreads lists locally persisted threads, most recently updated
+// first, offset by the given number of results for incremental pagination.
+func (b *openAIBackend) SearchThreads(ctx context.Context, limit, offset int) ([]Thread, error) {
+	dir, err := openAIThreadsDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var threads []Thread
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		threadID := strings.TrimSuffix(entry.Name(), ".json")
+		thread, err := b.loadThread(threadID)
+		if err != nil {
+			continue
+		}
+		threads = append(threads, *thread)
+	}
+
+	sort.Slice(threads, func(i, j int) bool {
+		return threads[i].UpdatedAt > threads[j].UpdatedAt
+	})
+
+	if offset > 0 {
+		if offset >= len(threads) {
+			return nil, nil
+		}
+		threads = threads[offset:]
+	}
+	if limit > 0 && len(threads) > limit {
+		threads = threads[:limit]
+	}
+	return threads, nil
+}
+
+// GetThread returns a locally persisted thread. selectFields is accepted for
+// interface parity but ignored since the whole thread is always local.
+func (b *openAIBackend) GetThread(ctx context.Context, threadID string, selectFields ...string) (*Thread, error) {
+	return b.loadThread(threadID)
+}
+
+// GetThreadState returns the thread's message history as its state.
+func (b *openAIBackend) GetThreadState(ctx context.Context, threadID string) (*ThreadState, error) {
+	thread, err := b.loadThread(threadID)
+	if err != nil {
+		return nil, err
+	}
+	return &ThreadState{Values: thread.Values}, nil
+}
+
+// UpdateThreadState overwrites the locally persisted thread's values.
+func (b *openAIBackend) UpdateThreadState(ctx context.Context, threadID string, values map[string]interface{}) (*ThreadState, error) {
+	thread, err := b.loadThread(threadID)
+	if err != nil {
+		return nil, err
+	}
+	thread.Values = values
+	thread.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	if err := b.saveThread(thread); err != nil {
+		return nil, err
+	}
+	return &ThreadState{Values: thread.Values}, nil
+}
+
+// DeleteThread removes the thread's locally persisted state file.
+func (b *openAIBackend) DeleteThread(ctx context.Context, threadID string) error {
+	path, err := b.threadPath(threadID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// UpdateThreadMetadata merges metadata into the locally persisted thread's
+// existing metadata.
+func (b *openAIBackend) UpdateThreadMetadata(ctx context.Context, threadID string, metadata map[string]interface{}) (*Thread, error) {
+	thread, err := b.loadThread(threadID)
+	if err != nil {
+		return nil, err
+	}
+	if thread.Metadata == nil {
+		thread.Metadata = map[string]interface{}{}
+	}
+	for k, v := range metadata {
+		thread.Metadata[k] = v
+	}
+	thread.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	if err := b.saveThread(thread); err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+// openAIChatRequest is the request body for /v1/chat/completions.
+type openAIChatRequest struct {
+	Model    string              `json:"model"`
+	Messages []openAIChatMessage `json:"messages"`
+	Stream   bool                `json:"stream"`
+}
+
+type openAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAIChatChunk is a single "data: {...}" SSE chunk from the streaming
+// chat completions response.
+type openAIChatChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+// StreamRun appends the user message to the local thread, streams the
+// completion from the OpenAI-compatible endpoint, and persists the
+// assistant's reply once the stream completes. onToolCall is accepted for
+// interface parity but never called: plain chat-completions endpoints don't
+// emit tool calls or interrupts.
+func (b *openAIBackend) StreamRun(ctx context.Context, threadID string, assistantID string, userMessage string, onToken TokenCallback, onToolCall ToolCallCallback, opts ...RequestOption) error {
+	thread, err := b.loadThread(threadID)
+	if err != nil {
+		return fmt.Errorf("failed to load thread: %w", err)
+	}
+
+	history := GetMessages(thread.Values)
+
+	var chatMessages []openAIChatMessage
+	if b.systemPrompt != "" {
+		chatMessages = append(chatMessages, openAIChatMessage{Role: "system", Content: b.systemPrompt})
+	}
+	for _, msg := range history {
+		chatMessages = append(chatMessages, openAIChatMessage{Role: msg.Role, Content: msg.Content})
+	}
+	chatMessages = append(chatMessages, openAIChatMessage{Role: "user", Content: userMessage})
+
+	reqBody := openAIChatRequest{
+		Model:    b.model,
+		Messages: chatMessages,
+		Stream:   true,
+	}
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/v1/chat/completions", b.endpoint)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return err
+	}
+	for k, v := range b.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("error closing response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to create chat completion: %s", resp.Status)
+	}
+
+	var reply strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" || data == "" {
+			continue
+		}
+
+		var chunk openAIChatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			continue
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			reply.WriteString(choice.Delta.Content)
+			onToken(choice.Delta.Content)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	history = append(history,
+		Message{Role: "user", Content: userMessage},
+		Message{Role: "assistant", Content: reply.String()},
+	)
+	messages := make([]interface{}, len(history))
+	for i, msg := range history {
+		messages[i] = map[string]interface{}{"role": msg.Role, "content": msg.Content}
+	}
+	thread.Values = map[string]interface{}{"messages": messages}
+	thread.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	return b.saveThread(thread)
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/wfh/lsc/internal/config"
+)
+
+// requestFactory builds a fresh *http.Request for each attempt, since a
+// request whose body has already been read can't be replayed.
+type requestFactory func(ctx context.Context) (*http.Request, error)
+
+// doWithRetry sends the request built by newReq, retrying on network errors
+// and the status codes in policy.RetryOnStatus with full-jitter exponential
+// backoff: sleep = rand(0, min(cap, base*2^attempt)). It honors a
+// Retry-After header on retryable responses and is cancellable via ctx.
+func doWithRetry(ctx context.Context, httpClient *http.Client, policy config.RetryPolicy, newReq requestFactory) (*http.Response, error) {
+	policy = policy.WithDefaults()
+
+	var lastErr error
+	var nextDelay time.Duration
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(nextDelay):
+			}
+		}
+
+		req, err := newReq(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			nextDelay = fullJitterBackoff(policy.BaseDelay, policy.CapDelay, attempt+1)
+			continue
+		}
+
+		if !isRetryableStatus(policy, resp.StatusCode) {
+			return resp, nil
+		}
+
+		lastErr = &retryableStatusError{status: resp.Status, statusCode: resp.StatusCode}
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		if retryAfter > 0 {
+			nextDelay = retryAfter
+		} else {
+			nextDelay = fullJitterBackoff(policy.BaseDelay, policy.CapDelay, attempt+1)
+		}
+	}
+
+	return nil, lastErr
+}
+
+// retryableStatusError records the final retryable status code seen once
+// retries are exhausted.
+type retryableStatusError struct {
+	status     string
+	statusCode int
+}
+
+func (e *retryableStatusError) Error() string {
+	return "request failed: " + e.status
+}
+
+func isRetryableStatus(policy config.RetryPolicy, statusCode int) bool {
+	for _, s := range policy.RetryOnStatus {
+		if s == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header value, which per RFC 7231 is
+// either a number of seconds or an HTTP-date. It returns 0 if absent or
+// unparseable.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// fullJitterBackoff implements the "full jitter" backoff strategy:
+// sleep = rand(0, min(cap, base*2^attempt)).
+func fullJitterBackoff(base, capDelay time.Duration, attempt int) time.Duration {
+	max := base << uint(attempt)
+	if max <= 0 || max > capDelay { // overflow or exceeds cap
+		max = capDelay
+	}
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
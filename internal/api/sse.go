@@ -6,8 +6,9 @@ import (
 	"strings"
 )
 
-// SSECallback is called for each SSE event
-type SSECallback func(event SSEEvent)
+// SSECallback is called for each SSE event. Return false to stop reading the
+// stream early, e.g. once an interrupt event has been handled.
+type SSECallback func(event SSEEvent) bool
 
 // ParseSSE reads an SSE stream and calls the callback for each event
 func ParseSSE(reader io.Reader, callback SSECallback) error {
@@ -23,7 +24,9 @@ func ParseSSE(reader io.Reader, callback SSECallback) error {
 			// Empty line = end of event
 			if len(dataLines) > 0 {
 				currentEvent.Data = strings.Join(dataLines, "\n")
-				callback(currentEvent)
+				if !callback(currentEvent) {
+					return nil
+				}
 			}
 			// Reset for next event
 			currentEvent = SSEEvent{}
@@ -65,3 +68,9 @@ func IsEndEvent(event SSEEvent) bool {
 func IsMessageEvent(event SSEEvent) bool {
 	return event.Event == "messages" || event.Event == "data"
 }
+
+// IsInterruptEvent checks if the event signals that the run has paused on a
+// LangGraph interrupt() call, awaiting human approval before it can resume.
+func IsInterruptEvent(event SSEEvent) bool {
+	return event.Event == "interrupt"
+}
@@ -0,0 +1,61 @@
+package api
+
+import "fmt"
+
+// ToolCall is a tool invocation rebuilt from the tool_call_chunks scattered
+// across a streamed AIMessageChunk. Args is raw JSON and may still be
+// incomplete while the call is streaming; callers should treat each
+// ToolCallCallback invocation as the latest snapshot, not a final value,
+// until the stream ends.
+type ToolCall struct {
+	ID   string
+	Name string
+	Args string
+}
+
+// ToolCallCallback is called with the accumulated state of a tool call each
+// time new tool_call_chunks for it arrive.
+type ToolCallCallback func(call ToolCall)
+
+// toolCallAccumulator merges tool_call_chunks scattered across multiple
+// MessageChunks into ToolCall values, keyed by Index (falling back to ID for
+// chunks that carry no index). Index is preferred because, per ToolCallChunk's
+// doc comment, Name and ID are typically only present on the first chunk for
+// a call, so keying by ID would put that first chunk and its later,
+// ID-less args-only fragments in different entries.
+type toolCallAccumulator struct {
+	byKey map[string]*ToolCall
+}
+
+func newToolCallAccumulator() *toolCallAccumulator {
+	return &toolCallAccumulator{byKey: map[string]*ToolCall{}}
+}
+
+func (a *toolCallAccumulator) key(chunk ToolCallChunk) string {
+	if chunk.Index != nil {
+		return fmt.Sprintf("index-%d", *chunk.Index)
+	}
+	if chunk.ID != "" {
+		return chunk.ID
+	}
+	return "0"
+}
+
+// apply merges chunk into its tool call's running state and returns the
+// updated value.
+func (a *toolCallAccumulator) apply(chunk ToolCallChunk) ToolCall {
+	key := a.key(chunk)
+	call, ok := a.byKey[key]
+	if !ok {
+		call = &ToolCall{}
+		a.byKey[key] = call
+	}
+	if chunk.ID != "" {
+		call.ID = chunk.ID
+	}
+	if chunk.Name != "" {
+		call.Name = chunk.Name
+	}
+	call.Args += chunk.Args
+	return *call
+}
@@ -48,15 +48,27 @@ type ContentBlock struct {
 
 // MessageChunk represents a streamed message chunk from the messages stream mode
 type MessageChunk struct {
-	Content          any            `json:"content"` // Can be string or []ContentBlock
-	Type             string         `json:"type"`
-	ID               string         `json:"id,omitempty"`
-	Name             string         `json:"name,omitempty"`
-	AdditionalKwargs map[string]any `json:"additional_kwargs,omitempty"`
-	ResponseMetadata map[string]any `json:"response_metadata,omitempty"`
-	ToolCalls        []any          `json:"tool_calls,omitempty"`
-	InvalidToolCalls []any          `json:"invalid_tool_calls,omitempty"`
-	UsageMetadata    any            `json:"usage_metadata,omitempty"`
+	Content          any             `json:"content"` // Can be string or []ContentBlock
+	Type             string          `json:"type"`
+	ID               string          `json:"id,omitempty"`
+	Name             string          `json:"name,omitempty"`
+	AdditionalKwargs map[string]any  `json:"additional_kwargs,omitempty"`
+	ResponseMetadata map[string]any  `json:"response_metadata,omitempty"`
+	ToolCalls        []any           `json:"tool_calls,omitempty"`
+	InvalidToolCalls []any           `json:"invalid_tool_calls,omitempty"`
+	ToolCallChunks   []ToolCallChunk `json:"tool_call_chunks,omitempty"`
+	UsageMetadata    any             `json:"usage_metadata,omitempty"`
+}
+
+// ToolCallChunk is a fragment of a tool call emitted while an AIMessageChunk
+// streams. Name and ID are typically only present on the first chunk for a
+// given call; Args arrives as successive partial-JSON fragments that must be
+// concatenated, in order, by ID (or by Index when a chunk carries no ID).
+type ToolCallChunk struct {
+	Name  string `json:"name,omitempty"`
+	Args  string `json:"args,omitempty"`
+	ID    string `json:"id,omitempty"`
+	Index *int   `json:"index,omitempty"`
 }
 
 // GetContent extracts the text content from a MessageChunk
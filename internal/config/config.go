@@ -3,15 +3,128 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Backend identifies which agent server implementation the CLI talks to.
+type Backend string
+
+const (
+	// BackendLangSmith is the default: a LangSmith/LangGraph Agent Server deployment.
+	BackendLangSmith Backend = "langsmith"
+	// BackendOpenAI talks to an OpenAI-compatible /v1/chat/completions endpoint,
+	// synthesizing threads locally since such servers are stateless.
+	BackendOpenAI Backend = "openai"
+)
+
 type Config struct {
 	Endpoint      string            `yaml:"endpoint"`
 	ApiKey        string            `yaml:"api_key"`
 	AssistantID   string            `yaml:"assistant_id"`
 	CustomHeaders map[string]string `yaml:"custom_headers,omitempty"`
+
+	Backend Backend        `yaml:"backend,omitempty"`
+	OpenAI  *OpenAIOptions `yaml:"openai,omitempty"`
+
+	RetryPolicy *RetryPolicy `yaml:"retry_policy,omitempty"`
+
+	// Agents are named bundles of assistant ID + system prompt override +
+	// always-attached RAG files + tool allow-list, selectable with -a/--agent
+	// or "/agent <name>" instead of a bare AssistantID.
+	Agents []Agent `yaml:"agents,omitempty"`
+	// DefaultAgent names the Agents entry used when -a/--agent isn't given.
+	// If empty (or not found), AssistantID is used directly.
+	DefaultAgent string `yaml:"default_agent,omitempty"`
+
+	// Theme names the chroma style used to syntax-highlight fenced code
+	// blocks in assistant output. Empty means ui.DefaultChromaStyle.
+	// Changeable at runtime with "/theme <name>".
+	Theme string `yaml:"theme,omitempty"`
+}
+
+// Agent is a named bundle of assistant configuration: which assistant to
+// run, what system prompt to use instead of its default, which files to
+// always attach for retrieval, and which tools it's allowed to call.
+type Agent struct {
+	Name         string `yaml:"name"`
+	AssistantID  string `yaml:"assistant_id"`
+	SystemPrompt string `yaml:"system_prompt,omitempty"`
+	// RAGFiles lists files or globs to attach to every run as context.
+	RAGFiles []string `yaml:"rag_files,omitempty"`
+	// Tools, if non-empty, restricts the assistant to this allow-list
+	// instead of whatever tools it's configured with by default.
+	Tools []string `yaml:"tools,omitempty"`
+}
+
+// FindAgent looks up an agent by name. It returns false if name is empty or
+// no agent with that name is configured.
+func (c *Config) FindAgent(name string) (*Agent, bool) {
+	if name == "" {
+		return nil, false
+	}
+	for i := range c.Agents {
+		if c.Agents[i].Name == name {
+			return &c.Agents[i], true
+		}
+	}
+	return nil, false
+}
+
+// RetryPolicy configures full-jitter exponential backoff retries for
+// transient HTTP failures (network errors, 429, and 5xx responses).
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Zero
+	// means use DefaultRetryPolicy's value.
+	MaxAttempts int `yaml:"max_attempts,omitempty"`
+	// BaseDelay is the backoff base ("base" in base * 2^attempt). Zero means
+	// use DefaultRetryPolicy's value.
+	BaseDelay time.Duration `yaml:"base_delay,omitempty"`
+	// CapDelay is the maximum backoff delay before jitter. Zero means use
+	// DefaultRetryPolicy's value.
+	CapDelay time.Duration `yaml:"cap_delay,omitempty"`
+	// RetryOnStatus lists HTTP status codes that should be retried in
+	// addition to network errors. Empty means use DefaultRetryPolicy's value.
+	RetryOnStatus []int `yaml:"retry_on_status,omitempty"`
+}
+
+// DefaultRetryPolicy returns the retry policy used when Config.RetryPolicy
+// (or one of its fields) is unset: 5 attempts, ~200ms base, 30s cap, retrying
+// on 429 and 5xx.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:   5,
+		BaseDelay:     200 * time.Millisecond,
+		CapDelay:      30 * time.Second,
+		RetryOnStatus: []int{429, 500, 502, 503, 504},
+	}
+}
+
+// WithDefaults fills in zero-valued fields from DefaultRetryPolicy.
+func (p RetryPolicy) WithDefaults() RetryPolicy {
+	d := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = d.BaseDelay
+	}
+	if p.CapDelay <= 0 {
+		p.CapDelay = d.CapDelay
+	}
+	if len(p.RetryOnStatus) == 0 {
+		p.RetryOnStatus = d.RetryOnStatus
+	}
+	return p
+}
+
+// OpenAIOptions configures the OpenAI-compatible backend.
+type OpenAIOptions struct {
+	// Model is the model name sent as "model" in the chat completions request.
+	Model string `yaml:"model,omitempty"`
+	// SystemPrompt, if set, is sent as the first message with role "system".
+	SystemPrompt string `yaml:"system_prompt,omitempty"`
 }
 
 // ConfigDir returns the path to the config directory (~/.lsc)
@@ -0,0 +1,295 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/wfh/lsc/internal/api"
+	"github.com/wfh/lsc/internal/config"
+)
+
+// helpCommand lists every registered command.
+type helpCommand struct{}
+
+func (helpCommand) Name() string { return "/help" }
+func (helpCommand) Help() string { return "List available slash commands" }
+func (helpCommand) Run(ctx context.Context, sess *Session, args string) tea.Cmd {
+	var sb strings.Builder
+	sb.WriteString("Available commands:\n")
+	for _, cmd := range List() {
+		fmt.Fprintf(&sb, "  %-18s %s\n", cmd.Name(), cmd.Help())
+	}
+	text := sb.String()
+	return func() tea.Msg { return ResultMsg{Text: text} }
+}
+
+// threadsCommand opens the full-screen conversation list view.
+type threadsCommand struct{}
+
+func (threadsCommand) Name() string { return "/threads" }
+func (threadsCommand) Help() string { return "Browse, switch, rename, or delete threads" }
+func (threadsCommand) Run(ctx context.Context, sess *Session, args string) tea.Cmd {
+	return func() tea.Msg { return OpenThreadListMsg{} }
+}
+
+// switchCommand switches the session to a different thread.
+type switchCommand struct{}
+
+func (switchCommand) Name() string { return "/switch" }
+func (switchCommand) Help() string { return "/switch <id> - switch to another thread" }
+func (switchCommand) Run(ctx context.Context, sess *Session, args string) tea.Cmd {
+	threadID := strings.TrimSpace(args)
+	return func() tea.Msg {
+		if threadID == "" {
+			return ErrMsg{Err: fmt.Errorf("usage: /switch <thread-id>")}
+		}
+		state, err := sess.Client.GetThreadState(ctx, threadID)
+		if err != nil {
+			return ErrMsg{Err: fmt.Errorf("failed to load thread %s: %w", threadID, err)}
+		}
+		sess.ThreadID = threadID
+		sess.History = api.GetMessages(state.Values)
+		sess.HistoryChanged = true
+		return ResultMsg{Text: fmt.Sprintf("Switched to thread %s", threadID)}
+	}
+}
+
+// forkCommand creates a new thread seeded with the current history.
+type forkCommand struct{}
+
+func (forkCommand) Name() string { return "/fork" }
+func (forkCommand) Help() string { return "Fork the current thread into a new one" }
+func (forkCommand) Run(ctx context.Context, sess *Session, args string) tea.Cmd {
+	return func() tea.Msg {
+		thread, err := sess.Client.CreateThread(ctx)
+		if err != nil {
+			return ErrMsg{Err: fmt.Errorf("failed to fork thread: %w", err)}
+		}
+
+		messages := make([]interface{}, len(sess.History))
+		for i, msg := range sess.History {
+			messages[i] = map[string]interface{}{"role": msg.Role, "content": msg.Content}
+		}
+		if _, err := sess.Client.UpdateThreadState(ctx, thread.ThreadID, map[string]interface{}{"messages": messages}); err != nil {
+			return ErrMsg{Err: fmt.Errorf("failed to seed forked thread: %w", err)}
+		}
+
+		sess.ThreadID = thread.ThreadID
+		sess.HistoryChanged = true
+		return ResultMsg{Text: fmt.Sprintf("Forked into new thread %s", thread.ThreadID)}
+	}
+}
+
+// rewindCommand truncates the thread history by n exchanges.
+type rewindCommand struct{}
+
+func (rewindCommand) Name() string { return "/rewind" }
+func (rewindCommand) Help() string { return "/rewind <n> - drop the last n exchanges" }
+func (rewindCommand) Run(ctx context.Context, sess *Session, args string) tea.Cmd {
+	n, err := strconv.Atoi(strings.TrimSpace(args))
+	return func() tea.Msg {
+		if err != nil || n <= 0 {
+			return ErrMsg{Err: fmt.Errorf("usage: /rewind <n>")}
+		}
+		drop := rewindDropCount(sess.History, n)
+		sess.History = sess.History[:len(sess.History)-drop]
+		sess.HistoryChanged = true
+
+		messages := make([]interface{}, len(sess.History))
+		for i, msg := range sess.History {
+			messages[i] = map[string]interface{}{"role": msg.Role, "content": msg.Content}
+		}
+		if _, err := sess.Client.UpdateThreadState(ctx, sess.ThreadID, map[string]interface{}{"messages": messages}); err != nil {
+			return ErrMsg{Err: fmt.Errorf("failed to rewind thread: %w", err)}
+		}
+		return ResultMsg{Text: fmt.Sprintf("Rewound %d exchange(s)", n)}
+	}
+}
+
+// rewindDropCount returns how many trailing entries of history to drop to
+// rewind n exchanges. An exchange runs from one "user" message up to (but
+// not including) the next, so any assistant replies and the "tool"/"system"/
+// "error" entries interleaved with them (tool calls, an interrupted turn's
+// stopped marker, ...) are dropped along with the user message that started
+// them, rather than assuming a flat two-messages-per-exchange count.
+func rewindDropCount(history []api.Message, n int) int {
+	dropped := 0
+	exchanges := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		dropped++
+		if history[i].Role == "user" {
+			exchanges++
+			if exchanges == n {
+				break
+			}
+		}
+	}
+	return dropped
+}
+
+// systemCommand overrides the session's system prompt.
+type systemCommand struct{}
+
+func (systemCommand) Name() string { return "/system" }
+func (systemCommand) Help() string { return "/system <prompt> - override the system prompt" }
+func (systemCommand) Run(ctx context.Context, sess *Session, args string) tea.Cmd {
+	prompt := strings.TrimSpace(args)
+	return func() tea.Msg {
+		sess.SystemPrompt = prompt
+		return ResultMsg{Text: "System prompt updated"}
+	}
+}
+
+// agentCommand switches the session to a different configured agent,
+// swapping in its assistant ID and system prompt override. With no args it
+// lists the agents available to switch to.
+type agentCommand struct{}
+
+func (agentCommand) Name() string { return "/agent" }
+func (agentCommand) Help() string { return "/agent [name] - switch agents, or list them" }
+func (agentCommand) Run(ctx context.Context, sess *Session, args string) tea.Cmd {
+	name := strings.TrimSpace(args)
+	return func() tea.Msg {
+		if name == "" {
+			if len(sess.Agents) == 0 {
+				return ResultMsg{Text: "No agents configured. Add one with /configure."}
+			}
+			var sb strings.Builder
+			sb.WriteString("Available agents:\n")
+			for _, a := range sess.Agents {
+				fmt.Fprintf(&sb, "  %-18s %s\n", a.Name, a.AssistantID)
+			}
+			return ResultMsg{Text: sb.String()}
+		}
+
+		for _, a := range sess.Agents {
+			if a.Name == name {
+				sess.AssistantID = a.AssistantID
+				sess.SystemPrompt = a.SystemPrompt
+				sess.RAGFiles = a.RAGFiles
+				sess.Tools = a.Tools
+				return ResultMsg{Text: fmt.Sprintf("Switched to agent %q (assistant %s)", a.Name, a.AssistantID)}
+			}
+		}
+		return ErrMsg{Err: fmt.Errorf("no such agent: %s (try /agent to list)", name)}
+	}
+}
+
+// themeCommand switches the chroma style used to highlight fenced code
+// blocks in assistant output. With no args it reports the current theme.
+type themeCommand struct{}
+
+func (themeCommand) Name() string { return "/theme" }
+func (themeCommand) Help() string { return "/theme [name] - switch the code-block color theme" }
+func (themeCommand) Run(ctx context.Context, sess *Session, args string) tea.Cmd {
+	name := strings.TrimSpace(args)
+	return func() tea.Msg { return SetThemeMsg{Name: name} }
+}
+
+// editCommand opens an empty $EDITOR buffer for composing a long message,
+// loaded into the input field once the editor exits.
+type editCommand struct{}
+
+func (editCommand) Name() string { return "/edit" }
+func (editCommand) Help() string { return "Compose a long message in $EDITOR" }
+func (editCommand) Run(ctx context.Context, sess *Session, args string) tea.Cmd {
+	return func() tea.Msg { return OpenComposeMsg{} }
+}
+
+// exportCommand writes the current history to a file as markdown or JSON.
+type exportCommand struct{}
+
+func (exportCommand) Name() string { return "/export" }
+func (exportCommand) Help() string { return "/export md|json - export the conversation to a file" }
+func (exportCommand) Run(ctx context.Context, sess *Session, args string) tea.Cmd {
+	format := strings.TrimSpace(args)
+	return func() tea.Msg {
+		var data []byte
+		var ext string
+		switch format {
+		case "json":
+			var err error
+			data, err = json.MarshalIndent(sess.History, "", "  ")
+			if err != nil {
+				return ErrMsg{Err: err}
+			}
+			ext = "json"
+		case "md", "":
+			var sb strings.Builder
+			for _, msg := range sess.History {
+				fmt.Fprintf(&sb, "**%s:** %s\n\n", msg.Role, msg.Content)
+			}
+			data = []byte(sb.String())
+			ext = "md"
+		default:
+			return ErrMsg{Err: fmt.Errorf("usage: /export md|json")}
+		}
+
+		path := fmt.Sprintf("%s.%s", sess.ThreadID, ext)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return ErrMsg{Err: fmt.Errorf("failed to export conversation: %w", err)}
+		}
+		return ResultMsg{Text: fmt.Sprintf("Exported conversation to %s", path)}
+	}
+}
+
+// saveCommand records a friendly name for the current thread in
+// ~/.lsc/saved_threads.json so it can be found again later.
+type saveCommand struct{}
+
+func (saveCommand) Name() string { return "/save" }
+func (saveCommand) Help() string { return "/save <name> - save the current thread under a name" }
+func (saveCommand) Run(ctx context.Context, sess *Session, args string) tea.Cmd {
+	name := strings.TrimSpace(args)
+	return func() tea.Msg {
+		if name == "" {
+			return ErrMsg{Err: fmt.Errorf("usage: /save <name>")}
+		}
+		if err := saveNamedThread(name, sess.ThreadID); err != nil {
+			return ErrMsg{Err: fmt.Errorf("failed to save thread: %w", err)}
+		}
+		return ResultMsg{Text: fmt.Sprintf("Saved thread %s as %q", sess.ThreadID, name)}
+	}
+}
+
+func savedThreadsPath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "saved_threads.json"), nil
+}
+
+func saveNamedThread(name, threadID string) error {
+	path, err := savedThreadsPath()
+	if err != nil {
+		return err
+	}
+
+	saved := map[string]string{}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &saved)
+	}
+	saved[name] = threadID
+
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
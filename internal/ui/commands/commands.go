@@ -0,0 +1,62 @@
+// Package commands implements the slash-command subsystem for the chat UI:
+// lines starting with "/" are intercepted before being sent as a user
+// message and dispatched to a registered Command instead.
+package commands
+
+import (
+	"context"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Command is a single slash command (e.g. "/rewind"). Third parties can
+// register additional commands with Register.
+type Command interface {
+	// Name is the command's invocation text, including the leading slash
+	// (e.g. "/help").
+	Name() string
+	// Help is a one-line description shown by "/help" and tab-completion.
+	Help() string
+	// Run executes the command against the current session. args is the
+	// remainder of the input line after the command name, trimmed of
+	// leading whitespace.
+	Run(ctx context.Context, sess *Session, args string) tea.Cmd
+}
+
+var registry = map[string]Command{}
+
+// Register adds (or replaces) a command in the default registry.
+func Register(cmd Command) {
+	registry[cmd.Name()] = cmd
+}
+
+// Lookup returns the command registered under name, if any.
+func Lookup(name string) (Command, bool) {
+	cmd, ok := registry[name]
+	return cmd, ok
+}
+
+// List returns every registered command, sorted by name.
+func List() []Command {
+	cmds := make([]Command, 0, len(registry))
+	for _, cmd := range registry {
+		cmds = append(cmds, cmd)
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name() < cmds[j].Name() })
+	return cmds
+}
+
+func init() {
+	Register(helpCommand{})
+	Register(threadsCommand{})
+	Register(switchCommand{})
+	Register(forkCommand{})
+	Register(rewindCommand{})
+	Register(systemCommand{})
+	Register(exportCommand{})
+	Register(saveCommand{})
+	Register(agentCommand{})
+	Register(themeCommand{})
+	Register(editCommand{})
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"github.com/wfh/lsc/internal/api"
+	"github.com/wfh/lsc/internal/config"
+)
+
+// Session is the chat state a Command is allowed to read and mutate. The
+// chat UI owns the Session and applies whatever changes a Command makes
+// (new ThreadID, truncated History, ...) after Run returns.
+type Session struct {
+	Client      *api.Client
+	ThreadID    string
+	AssistantID string
+	History     []api.Message
+	// SystemPrompt overrides the assistant's default system prompt for the
+	// rest of the session, set via "/system" or by switching agents.
+	SystemPrompt string
+	// RAGFiles lists files or globs whose contents are attached to every run
+	// as retrieval context, set by switching to an agent that defines them.
+	RAGFiles []string
+	// Tools, if non-empty, restricts the assistant to this allow-list for
+	// every run, set by switching to an agent that defines one.
+	Tools []string
+	// Agents are the agents available to switch between via "/agent". It's
+	// populated once from config.Config.Agents when the session starts.
+	Agents []config.Agent
+	// Quit is set by commands (like a future "/quit" registered externally)
+	// that want the chat loop to exit after Run returns.
+	Quit bool
+	// HistoryChanged is set by commands that repoint History at a different
+	// thread or truncate it (/switch, /fork, /rewind), so the chat UI knows
+	// to rebuild the rendered transcript from History instead of just
+	// appending the command's result line to whatever was already on screen.
+	HistoryChanged bool
+}
+
+// ResultMsg carries a line of output a Command wants printed to the chat
+// transcript, e.g. the reply to "/help" or "/threads".
+type ResultMsg struct {
+	Text string
+}
+
+// ErrMsg carries an error a Command encountered.
+type ErrMsg struct {
+	Err error
+}
+
+func (e ErrMsg) Error() string { return e.Err.Error() }
+
+// OpenThreadListMsg asks the chat UI to suspend and show the full-screen
+// conversation list view (see "/threads"), since a second tea.Program can't
+// be nested inside the one already running.
+type OpenThreadListMsg struct{}
+
+// SetThemeMsg asks the chat UI to switch (or report, if Name is empty) the
+// chroma style used to highlight fenced code blocks in assistant output.
+type SetThemeMsg struct {
+	Name string
+}
+
+// OpenComposeMsg asks the chat UI to open an empty $EDITOR buffer for
+// composing a long message (see "/edit").
+type OpenComposeMsg struct{}
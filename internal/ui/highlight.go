@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// DefaultChromaStyle is the chroma style used when Config.Theme is empty or
+// names a style chroma doesn't recognize.
+const DefaultChromaStyle = "monokai"
+
+// currentTheme is the chroma style name currently applied to fenced code
+// blocks, swappable at runtime with "/theme <name>".
+var currentTheme = DefaultChromaStyle
+
+// CurrentTheme returns the chroma style name currently applied to fenced
+// code blocks.
+func CurrentTheme() string {
+	return currentTheme
+}
+
+// SetTheme switches the chroma style used to highlight fenced code blocks,
+// returning false (and leaving the theme unchanged) if name isn't a
+// registered chroma style.
+func SetTheme(name string) bool {
+	if _, ok := styles.Registry[name]; !ok {
+		return false
+	}
+	currentTheme = name
+	return true
+}
+
+// RenderMarkdown word-wraps prose to width and syntax-highlights fenced code
+// blocks with chroma, using the style set by SetTheme. It's the rendering
+// pipeline for both finalized messages (cached in Model.highlightCache) and
+// the in-flight tail of a streaming message, so it must tolerate a fence
+// left open at the end of content (tokens arrive mid-fence while streaming).
+func RenderMarkdown(content string, width int) string {
+	if width <= 0 {
+		width = 80
+	}
+
+	blocks := splitFences(content)
+	parts := make([]string, len(blocks))
+	for i, b := range blocks {
+		if b.fenced {
+			parts[i] = highlightCode(b.text, b.lang)
+		} else {
+			parts[i] = wordwrap.String(b.text, width)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// markdownBlock is either a run of prose or the contents of one fenced code
+// block (` ```lang ... ``` `).
+type markdownBlock struct {
+	text   string
+	lang   string
+	fenced bool
+}
+
+// splitFences splits content into alternating prose and fenced-code blocks.
+// A fence left open at the end of content (the common case mid-stream) is
+// still returned as a fenced block, highlighted as far as it's arrived.
+func splitFences(content string) []markdownBlock {
+	var blocks []markdownBlock
+	lines := strings.Split(content, "\n")
+
+	var cur strings.Builder
+	inFence := false
+	lang := ""
+
+	flush := func(fenced bool) {
+		if cur.Len() == 0 {
+			return
+		}
+		blocks = append(blocks, markdownBlock{
+			text:   strings.TrimSuffix(cur.String(), "\n"),
+			lang:   lang,
+			fenced: fenced,
+		})
+		cur.Reset()
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if inFence {
+				flush(true)
+				inFence = false
+				lang = ""
+			} else {
+				flush(false)
+				inFence = true
+				lang = strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			}
+			continue
+		}
+		cur.WriteString(line)
+		cur.WriteString("\n")
+	}
+	flush(inFence)
+
+	return blocks
+}
+
+// highlightCode renders code through chroma's terminal-256 formatter using
+// the current theme, falling back to the unhighlighted code on any lexer or
+// formatter error.
+func highlightCode(code, lang string) string {
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, code)
+	if err != nil {
+		return code
+	}
+
+	var sb strings.Builder
+	if err := formatters.TTY256.Format(&sb, styles.Get(currentTheme), iterator); err != nil {
+		return code
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
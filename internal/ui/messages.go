@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wfh/lsc/internal/api"
+)
+
+// FormatMessage formats a message for display, word-wrapping prose and
+// syntax-highlighting fenced code blocks to fit width (see RenderMarkdown).
+func FormatMessage(msg api.Message, width int) string {
+	switch msg.Role {
+	case "user", "human":
+		return fmt.Sprintf("%s%s", UserLabel, RenderMarkdown(msg.Content, width))
+	case "assistant", "ai":
+		return fmt.Sprintf("%s%s", AssistantLabel, RenderMarkdown(msg.Content, width))
+	default:
+		return fmt.Sprintf("[%s] %s", msg.Role, msg.Content)
+	}
+}
+
+// FormatHistory formats a list of messages for display
+func FormatHistory(messages []api.Message) string {
+	var sb strings.Builder
+	for i, msg := range messages {
+		sb.WriteString(FormatMessage(msg, 0))
+		if i < len(messages)-1 {
+			sb.WriteString("\n\n")
+		}
+	}
+	return sb.String()
+}
+
+// ToolCallText renders a streaming tool call as a collapsed one-line
+// "name(args)" string, with no styling applied, so it can be stored as raw
+// message content (e.g. for clipboard copy) and styled separately at
+// display time.
+func ToolCallText(call api.ToolCall) string {
+	args := call.Args
+	const maxArgsLen = 60
+	if len(args) > maxArgsLen {
+		args = args[:maxArgsLen-3] + "..."
+	}
+	return fmt.Sprintf("🔧 %s(%s)", call.Name, args)
+}
+
+// FormatToolCall formats a streaming tool call as a collapsed one-line block,
+// distinct from assistant text.
+func FormatToolCall(call api.ToolCall) string {
+	return ToolCallStyle.Render(ToolCallText(call))
+}
+
+// formatViewMessage renders one entry of a Model's structured transcript.
+// Besides the ordinary chat roles, it understands the synthetic roles the
+// chat model uses to keep tool calls, system notices, and errors in
+// chronological order alongside the conversation: "tool", "system", and
+// "error". In all three cases msg.Content holds the raw, unstyled text so it
+// can be copied to the clipboard or opened in $EDITOR as-is.
+func formatViewMessage(msg api.Message, width int) string {
+	switch msg.Role {
+	case "tool":
+		return ToolCallStyle.Render(msg.Content)
+	case "system":
+		return PrintSystem(msg.Content)
+	case "error":
+		return PrintError(msg.Content)
+	default:
+		return FormatMessage(msg, width)
+	}
+}
+
+// PrintSystem prints a system message
+func PrintSystem(msg string) string {
+	return SystemStyle.Render(msg)
+}
+
+// PrintError prints an error message
+func PrintError(msg string) string {
+	return ErrorStyle.Render("Error: " + msg)
+}
+
+// GetThreadPreview returns a preview string for a thread
+func GetThreadPreview(thread api.Thread) string {
+	messages := api.GetMessages(thread.Values)
+	if len(messages) == 0 {
+		return "(empty)"
+	}
+
+	// Get first user message as preview
+	for _, msg := range messages {
+		if msg.Role == "user" || msg.Role == "human" {
+			preview := msg.Content
+			if len(preview) > 50 {
+				preview = preview[:47] + "..."
+			}
+			return fmt.Sprintf("\"%s\"", preview)
+		}
+	}
+
+	// Fallback to first message
+	preview := messages[0].Content
+	if len(preview) > 50 {
+		preview = preview[:47] + "..."
+	}
+	return fmt.Sprintf("\"%s\"", preview)
+}
@@ -3,16 +3,22 @@ package ui
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
-	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/wfh/lsc/internal/api"
+	"github.com/wfh/lsc/internal/config"
+	"github.com/wfh/lsc/internal/ui/commands"
 )
 
 // State represents the current UI state
@@ -22,22 +28,94 @@ const (
 	StateInput State = iota
 	StateWaiting
 	StateStreaming
+	// StateConfirm is entered when a run pauses on a LangGraph interrupt()
+	// and the model is waiting for a y/n decision before calling ResumeRun.
+	StateConfirm
 )
 
-// Model is the main Bubbletea model for the chat interface
+// focusState selects which part of the UI vi-style keys and Tab apply to.
+type focusState int
+
+const (
+	// focusInput routes keys to the textarea, as in a normal chat prompt.
+	focusInput focusState = iota
+	// focusMessages routes keys to the transcript: vi-style scrolling and
+	// selecting a message to copy or view in $EDITOR.
+	focusMessages
+)
+
+// footerHeight reserves room below the viewport for the input/spinner/
+// confirm line(s), including their surrounding blank lines.
+const footerHeight = 4
+
+// Model is the persistent Bubble Tea model for the chat interface. It owns
+// the session for the lifetime of the program: streaming, slash commands,
+// resizing, and /configure are all handled as messages against this one
+// model, instead of rebooting a tea.Program per turn.
 type Model struct {
-	client      *api.Client
-	assistantID string
-	threadID    string
+	sess *commands.Session
+
+	viewport viewport.Model
+	textarea textarea.Model
+	spinner  spinner.Model
+	ready    bool
+	width    int
+
+	state State
+	focus focusState
+
+	// messages is the finalized transcript, in chronological order. Besides
+	// the ordinary "user"/"assistant" roles it holds synthetic "tool",
+	// "system", and "error" entries so that everything a user sees is
+	// selectable and scrollable through the same viewport.
+	messages []api.Message
+	// messageOffsets[i] is the line (not byte) offset of messages[i] within
+	// the rendered transcript. It's recomputed by renderTranscript whenever
+	// the message list or viewport width changes, since word-wrapping inside
+	// the viewport can shift line counts.
+	messageOffsets []int
+	// selected is the index into messages currently highlighted in
+	// focusMessages mode, or -1 if nothing has been selected yet.
+	selected int
+
+	// highlightCache[i] is the rendered (word-wrapped, syntax-highlighted)
+	// form of messages[i], computed once and reused on every render since
+	// re-running chroma over a long finalized message on every keystroke
+	// would be wasteful. It's invalidated wholesale whenever highlightWidth
+	// goes stale, since word-wrapping depends on viewport width.
+	highlightCache []string
+	// highlightWidth is the viewport width highlightCache was rendered at.
+	highlightWidth int
+
+	currentResponse strings.Builder // assistant text for the in-flight turn
+	toolCalls       map[string]api.ToolCall
+	toolCallOrder   []string
+
+	// status is an ephemeral line shown in the footer (e.g. "Copied to
+	// clipboard") instead of being injected into the transcript itself.
+	status string
+
+	replyChan     chan string
+	toolCallChan  chan api.ToolCall
+	replyDoneChan chan error
+
+	// streamCancel cancels the in-flight StreamRun call, if any (StateWaiting
+	// or StateStreaming). It's nil whenever no turn is in flight.
+	streamCancel context.CancelFunc
+	// stopped records that the current turn was cut short by Ctrl+C, so
+	// StreamDoneMsg (which follows shortly, carrying ctx.Err()) can show a
+	// "stopped" marker instead of the usual error message.
+	stopped bool
+
+	pendingInterrupt *api.InterruptError
 
-	textInput textinput.Model
-	spinner   spinner.Model
+	completions   []int // indices into slashCommands
+	completionIdx int
+	showComplete  bool
+	ctrlCPressed  bool
 
-	state           State
-	currentResponse strings.Builder
-	output          strings.Builder // Accumulated output to print
-	err             error
-	quitting        bool
+	quitting bool
+	err      error
 }
 
 // TokenMsg is sent when a token is received from the stream
@@ -45,85 +123,130 @@ type TokenMsg struct {
 	Token string
 }
 
+// ToolCallMsg is sent when a streaming tool call is created or updated.
+type ToolCallMsg struct {
+	Call api.ToolCall
+}
+
 // StreamDoneMsg is sent when the stream is complete
 type StreamDoneMsg struct {
 	Err error
 }
 
-// NewModel creates a new chat model
-func NewModel(client *api.Client, assistantID, threadID string) Model {
-	ti := textinput.New()
-	ti.Placeholder = "Type a message..."
-	ti.Focus()
-	ti.CharLimit = 0 // No limit
-	ti.Width = 80
-	ti.Prompt = PromptStyle.Render("> ")
+// historyMsg carries the thread's message history, refreshed after a turn
+// completes so that /rewind, /fork, etc. performed server-side stay in sync.
+type historyMsg struct {
+	history []api.Message
+}
+
+// resumeDoneMsg is sent when a ResumeRun call following an interrupt
+// approval/rejection completes.
+type resumeDoneMsg struct {
+	err error
+}
+
+// editorDoneMsg is sent when the $EDITOR process launched to view a selected
+// message exits.
+type editorDoneMsg struct {
+	err error
+}
+
+// editorInputMsg is sent when the $EDITOR process launched to compose or
+// edit text (Ctrl+E, "/edit", or editing a prior message) exits. branchFrom
+// is the index of the message being edited (forking the thread from there
+// once content is resubmitted), or -1 if content should just be loaded back
+// into the input field.
+type editorInputMsg struct {
+	content    string
+	err        error
+	branchFrom int
+}
+
+// branchTruncatedMsg is sent once the UpdateThreadState call that truncates
+// the thread to just before an edited message completes, so the edited
+// content can be resubmitted as the next turn.
+type branchTruncatedMsg struct {
+	err         error
+	userMessage string
+}
+
+// NewModel creates a new chat model for an existing thread, seeded with its
+// prior history (if any). systemPrompt is the resolved agent's system prompt
+// override (empty if none), agents is the full list of configured agents so
+// "/agent" can switch between them, and ragFiles/tools are the resolved
+// agent's RAG files and tool allow-list (nil if it has none).
+func NewModel(client *api.Client, assistantID, threadID string, history []api.Message, systemPrompt string, agents []config.Agent, ragFiles, tools []string) Model {
+	ta := textarea.New()
+	ta.Placeholder = "Type a message... (Ctrl+J for newline)"
+	ta.Focus()
+	ta.CharLimit = 0
+	ta.ShowLineNumbers = false
+	ta.Prompt = PromptStyle.Render("> ")
+	ta.SetWidth(80)
+	ta.SetHeight(1)
+	ta.FocusedStyle.Base = lipgloss.NewStyle()
+	ta.BlurredStyle.Base = lipgloss.NewStyle()
+	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	ta.BlurredStyle.CursorLine = lipgloss.NewStyle()
+	ta.FocusedStyle.Placeholder = SystemStyle
+	ta.BlurredStyle.Placeholder = SystemStyle
 
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = SpinnerStyle
 
-	return Model{
-		client:      client,
-		assistantID: assistantID,
-		threadID:    threadID,
-		textInput:   ti,
-		spinner:     s,
-		state:       StateInput,
+	m := Model{
+		sess: &commands.Session{
+			Client:       client,
+			ThreadID:     threadID,
+			AssistantID:  assistantID,
+			History:      history,
+			SystemPrompt: systemPrompt,
+			RAGFiles:     ragFiles,
+			Tools:        tools,
+			Agents:       agents,
+		},
+		textarea: ta,
+		spinner:  s,
+		state:    StateInput,
+		focus:    focusInput,
+		selected: -1,
+		messages: append([]api.Message(nil), history...),
 	}
+	return m
 }
 
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return textinput.Blink
+	return textarea.Blink
 }
 
 // Update handles messages
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
-
 	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyCtrlD:
-			m.quitting = true
-			return m, tea.Quit
-
-		case tea.KeyEnter:
-			if m.state != StateInput {
-				return m, nil
-			}
-
-			input := strings.TrimSpace(m.textInput.Value())
-			if input == "" {
-				return m, nil
-			}
-
-			// Handle commands
-			if input == "/quit" || input == "/exit" {
-				m.quitting = true
-				return m, tea.Quit
-			}
-
-			if input == "/configure" {
-				// Signal to main to run configure
-				m.output.WriteString("\n")
-				m.quitting = true
-				m.err = fmt.Errorf("CONFIGURE")
-				return m, tea.Quit
-			}
-
-			// Print user message and start streaming
-			m.output.WriteString(fmt.Sprintf("%s%s\n", UserLabel, input))
-			m.textInput.Reset()
-			m.state = StateWaiting
-			m.currentResponse.Reset()
-
-			return m, tea.Batch(
-				m.spinner.Tick,
-				m.startStream(input),
-			)
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		if m.width != m.highlightWidth {
+			m.highlightCache = nil
+			m.highlightWidth = m.width
+		}
+		vpHeight := msg.Height - footerHeight
+		if vpHeight < 1 {
+			vpHeight = 1
 		}
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, vpHeight)
+			m.ready = true
+		} else {
+			m.viewport.Width = msg.Width
+			m.viewport.Height = vpHeight
+		}
+		m.textarea.SetWidth(msg.Width)
+		m.refreshViewport()
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
 
 	case spinner.TickMsg:
 		if m.state == StateWaiting {
@@ -131,348 +254,767 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.spinner, cmd = m.spinner.Update(msg)
 			return m, cmd
 		}
+		return m, nil
 
 	case TokenMsg:
+		if m.stopped {
+			return m, m.waitForStream()
+		}
 		if m.state == StateWaiting {
-			// First token - clear spinner and switch to streaming
 			m.state = StateStreaming
-			m.output.WriteString(AssistantLabel)
 		}
 		m.currentResponse.WriteString(msg.Token)
-		m.output.WriteString(msg.Token)
-		return m, nil
+		m.refreshViewport()
+		return m, m.waitForStream()
+
+	case ToolCallMsg:
+		if m.stopped {
+			return m, m.waitForStream()
+		}
+		if m.state == StateWaiting {
+			m.state = StateStreaming
+		}
+		if _, ok := m.toolCalls[msg.Call.ID]; !ok {
+			m.toolCallOrder = append(m.toolCallOrder, msg.Call.ID)
+		}
+		if m.toolCalls == nil {
+			m.toolCalls = map[string]api.ToolCall{}
+		}
+		m.toolCalls[msg.Call.ID] = msg.Call
+		m.refreshViewport()
+		return m, m.waitForStream()
 
 	case StreamDoneMsg:
+		if m.streamCancel != nil {
+			m.streamCancel()
+			m.streamCancel = nil
+		}
+		if m.stopped {
+			// The stream belongs to a turn we already flushed and finalized
+			// ourselves when Ctrl+C cancelled it; this is just the goroutine
+			// catching up with ctx.Err(), nothing left to do.
+			m.stopped = false
+			return m, nil
+		}
+		m.finalizeTurn()
 		if msg.Err != nil {
-			m.output.WriteString("\n")
-			m.output.WriteString(PrintError(msg.Err.Error()))
+			if ie, ok := msg.Err.(*api.InterruptError); ok {
+				m.pendingInterrupt = ie
+				m.state = StateConfirm
+				m.refreshViewport()
+				return m, nil
+			}
+			m.appendMessage(api.Message{Role: "error", Content: msg.Err.Error()})
+			m.state = StateInput
+			m.refreshViewport()
+			return m, textarea.Blink
 		}
-		m.output.WriteString("\n\n")
 		m.state = StateInput
-		return m, textinput.Blink
-	}
+		m.refreshViewport()
+		return m, tea.Batch(textarea.Blink, m.refreshHistoryCmd())
 
-	// Update text input
-	if m.state == StateInput {
-		var cmd tea.Cmd
-		m.textInput, cmd = m.textInput.Update(msg)
-		cmds = append(cmds, cmd)
-	}
+	case historyMsg:
+		m.sess.History = msg.history
+		return m, nil
 
-	return m, tea.Batch(cmds...)
-}
+	case resumeDoneMsg:
+		m.pendingInterrupt = nil
+		m.state = StateInput
+		if msg.err != nil {
+			m.appendMessage(api.Message{Role: "error", Content: msg.err.Error()})
+		} else {
+			m.appendMessage(api.Message{Role: "system", Content: "Run resumed."})
+		}
+		m.refreshViewport()
+		return m, textarea.Blink
 
-// View renders the UI
-func (m Model) View() string {
-	if m.quitting {
-		out := m.output.String()
-		if m.err == nil || m.err.Error() != "CONFIGURE" {
-			out += "Goodbye!\n"
+	case editorDoneMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("$EDITOR failed: %v", msg.err)
+		} else {
+			m.status = ""
 		}
-		return out
-	}
+		return m, nil
 
-	var sb strings.Builder
+	case editorInputMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("$EDITOR failed: %v", msg.err)
+			return m, nil
+		}
+		m.status = ""
+		if msg.branchFrom >= 0 {
+			return m.branchFromEdit(msg.branchFrom, msg.content)
+		}
+		m.textarea.SetValue(msg.content)
+		m.updateHeight()
+		return m, textarea.Blink
+
+	case branchTruncatedMsg:
+		if msg.err != nil {
+			m.appendMessage(api.Message{Role: "error", Content: fmt.Sprintf("failed to fork thread for edit: %v", msg.err)})
+			m.state = StateInput
+			m.refreshViewport()
+			return m, textarea.Blink
+		}
+		return m, m.beginTurn(msg.userMessage)
 
-	// Print accumulated output
-	sb.WriteString(m.output.String())
+	case commands.ResultMsg:
+		if m.sess.HistoryChanged {
+			m.messages = append([]api.Message(nil), m.sess.History...)
+			m.sess.HistoryChanged = false
+		}
+		m.appendMessage(api.Message{Role: "system", Content: msg.Text})
+		m.refreshViewport()
+		if m.sess.Quit {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		return m, nil
 
-	// Show current state
-	switch m.state {
-	case StateInput:
-		sb.WriteString(m.textInput.View())
-	case StateWaiting:
-		sb.WriteString(m.spinner.View())
-		sb.WriteString(" Thinking...")
-	case StateStreaming:
-		// Content is already in output
+	case commands.ErrMsg:
+		m.appendMessage(api.Message{Role: "error", Content: msg.Error()})
+		m.refreshViewport()
+		return m, nil
+
+	case commands.OpenThreadListMsg:
+		m.quitting = true
+		m.err = fmt.Errorf("THREADLIST")
+		return m, tea.Quit
+
+	case commands.OpenComposeMsg:
+		return m, m.openComposeEditorCmd("", -1)
+
+	case commands.SetThemeMsg:
+		if msg.Name == "" {
+			m.appendMessage(api.Message{Role: "system", Content: fmt.Sprintf("Current theme: %s", CurrentTheme())})
+		} else if !SetTheme(msg.Name) {
+			m.appendMessage(api.Message{Role: "error", Content: fmt.Sprintf("unknown theme: %s", msg.Name)})
+		} else {
+			m.invalidateHighlightCache()
+			m.appendMessage(api.Message{Role: "system", Content: fmt.Sprintf("Theme set to %s", msg.Name)})
+		}
+		m.refreshViewport()
+		return m, nil
 	}
 
-	return sb.String()
+	return m, nil
 }
 
-// startStream starts the streaming API call
-func (m *Model) startStream(userMessage string) tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
+// appendMessage adds an entry to the finalized transcript.
+func (m *Model) appendMessage(msg api.Message) {
+	m.messages = append(m.messages, msg)
+}
 
-		err := m.client.StreamRun(ctx, m.threadID, m.assistantID, userMessage, func(token string) {
-			// Send token to the model
-			// Note: This is a bit tricky with bubbletea - we'll use a channel approach
-		})
+// handleKey handles tea.KeyMsg, the one message type that depends on state.
+func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.state == StateConfirm {
+		switch msg.String() {
+		case "y", "Y", "enter":
+			return m, m.resumeCmd(true)
+		case "n", "N", "esc":
+			return m, m.resumeCmd(false)
+		}
+		return m, nil
+	}
 
-		return StreamDoneMsg{Err: err}
+	if msg.Type != tea.KeyCtrlC {
+		m.ctrlCPressed = false
 	}
-}
 
-// StreamChat runs the chat with streaming in a way that works with bubbletea
-// This is called from main to set up the program properly
-func StreamChat(client *api.Client, assistantID, threadID string, history []api.Message) error {
-	// Print history if any
-	if len(history) > 0 {
-		fmt.Println(FormatHistory(history))
-		fmt.Println()
+	if m.state == StateInput && m.showComplete && len(m.completions) > 0 {
+		switch msg.Type {
+		case tea.KeyTab, tea.KeyDown:
+			m.completionIdx = (m.completionIdx + 1) % len(m.completions)
+			return m, nil
+		case tea.KeyShiftTab, tea.KeyUp:
+			m.completionIdx = (m.completionIdx - 1 + len(m.completions)) % len(m.completions)
+			return m, nil
+		case tea.KeyEnter:
+			cmd := slashCommands[m.completions[m.completionIdx]]
+			m.textarea.Reset()
+			m.textarea.InsertString(cmd.name)
+			m.showComplete = false
+			m.completions = nil
+			return m, nil
+		case tea.KeyEsc:
+			m.showComplete = false
+			m.completions = nil
+			return m, nil
+		}
 	}
 
-	p := tea.NewProgram(NewModel(client, assistantID, threadID))
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		if m.state == StateWaiting || m.state == StateStreaming {
+			return m, m.cancelStream()
+		}
+		if m.ctrlCPressed {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		m.ctrlCPressed = true
+		return m, tea.Tick(time.Second, func(time.Time) tea.Msg { return ctrlCResetMsg{} })
 
-	// Run with a custom approach for streaming
-	model, err := runWithStreaming(p, client, assistantID, threadID)
-	if err != nil {
-		return err
+	case tea.KeyCtrlD:
+		m.quitting = true
+		return m, tea.Quit
+
+	case tea.KeyTab:
+		if m.state == StateInput {
+			if completions := m.getCompletions(); len(completions) > 0 {
+				m.completions = completions
+				m.completionIdx = 0
+				m.showComplete = true
+				return m, nil
+			}
+			m.toggleFocus()
+			return m, nil
+		}
 	}
 
-	// Check if we need to reconfigure
-	if m, ok := model.(Model); ok && m.err != nil && m.err.Error() == "CONFIGURE" {
-		return m.err
+	if m.focus == focusMessages {
+		return m.handleMessagesKey(msg)
 	}
 
-	return nil
-}
+	switch msg.Type {
+	case tea.KeyCtrlJ:
+		if m.state == StateInput {
+			m.textarea.InsertString("\n")
+			m.updateHeight()
+		}
+		return m, nil
 
-// runWithStreaming runs the bubbletea program with streaming support
-func runWithStreaming(p *tea.Program, client *api.Client, assistantID, threadID string) (tea.Model, error) {
-	// We need a different approach - use a simple input/output loop instead
-	// of bubbletea for better streaming support
-	return nil, fmt.Errorf("use RunChatLoop instead")
-}
+	case tea.KeyCtrlE:
+		if m.state == StateInput {
+			return m, m.openComposeEditorCmd(m.textarea.Value(), -1)
+		}
+		return m, nil
 
-// RunChatLoop runs a simple chat loop with streaming
-// This bypasses bubbletea for simplicity and better streaming support
-func RunChatLoop(client *api.Client, assistantID, threadID string, history []api.Message) error {
-	// Print history if any
-	if len(history) > 0 {
-		fmt.Println(FormatHistory(history))
-		fmt.Println()
+	case tea.KeyEnter:
+		if m.state != StateInput {
+			return m, nil
+		}
+		return m.submit()
 	}
 
-	s := spinner.New()
-	s.Spinner = spinner.Dot
-	s.Style = SpinnerStyle
+	if m.state != StateInput {
+		return m, nil
+	}
 
-	for {
-		// Create a textarea for multiline input
-		ta := textarea.New()
-		ta.Placeholder = "Type a message... (Shift+Enter for newline)"
-		ta.Focus()
-		ta.CharLimit = 0
-		ta.ShowLineNumbers = false
-		ta.Prompt = PromptStyle.Render("> ")
-		ta.SetWidth(80)
-		ta.SetHeight(1)
-
-		// Remove default styling (no background color)
-		ta.FocusedStyle.Base = lipgloss.NewStyle()
-		ta.BlurredStyle.Base = lipgloss.NewStyle()
-		ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
-		ta.BlurredStyle.CursorLine = lipgloss.NewStyle()
-		ta.FocusedStyle.Placeholder = SystemStyle
-		ta.BlurredStyle.Placeholder = SystemStyle
-
-		// Create a simple input program
-		inModel := inputModel{textarea: ta}
-		p := tea.NewProgram(inModel)
-		finalModel, err := p.Run()
-		if err != nil {
-			return err
-		}
+	var cmd tea.Cmd
+	m.textarea, cmd = m.textarea.Update(msg)
+	m.updateHeight()
 
-		resultModel := finalModel.(inputModel)
-		if resultModel.quitting {
-			fmt.Println("Goodbye!")
-			return nil
+	if strings.HasPrefix(m.textarea.Value(), "/") {
+		m.completions = m.getCompletions()
+		m.showComplete = len(m.completions) > 0
+		if m.completionIdx >= len(m.completions) {
+			m.completionIdx = 0
 		}
+	} else {
+		m.showComplete = false
+		m.completions = nil
+	}
+
+	return m, cmd
+}
 
-		input := resultModel.value
-		if input == "/configure" {
-			return fmt.Errorf("CONFIGURE")
+// toggleFocus switches between the input and the transcript. Entering
+// focusMessages selects the last message if nothing was selected yet.
+func (m *Model) toggleFocus() {
+	if m.focus == focusInput {
+		m.focus = focusMessages
+		if m.selected < 0 || m.selected >= len(m.messages) {
+			m.selected = len(m.messages) - 1
 		}
+	} else {
+		m.focus = focusInput
+	}
+	m.status = ""
+	m.refreshViewport()
+}
+
+// handleMessagesKey handles vi-style scrolling and message selection while
+// the transcript is focused (focusMessages).
+func (m Model) handleMessagesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.focus = focusInput
+		m.status = ""
+		m.refreshViewport()
+		return m, nil
+
+	case "j", "down":
+		m.selectMessage(m.selected + 1)
+		return m, nil
 
-		// Print user message (show newlines properly)
-		fmt.Printf("%s%s\n", UserLabel, input)
+	case "k", "up":
+		m.selectMessage(m.selected - 1)
+		return m, nil
 
-		// Show spinner and stream response
-		ctx := context.Background()
-		fmt.Print(s.View() + " Thinking...")
+	case "g":
+		m.selectMessage(0)
+		return m, nil
 
-		firstToken := true
-		err = client.StreamRun(ctx, threadID, assistantID, input, func(token string) {
-			if firstToken {
-				// Clear spinner line and print assistant label
-				fmt.Print("\r\033[K") // Clear line
-				fmt.Print(AssistantLabel)
-				firstToken = false
+	case "G":
+		m.selectMessage(len(m.messages) - 1)
+		return m, nil
+
+	case "ctrl+d":
+		m.viewport.HalfViewDown()
+		return m, nil
+
+	case "ctrl+u":
+		m.viewport.HalfViewUp()
+		return m, nil
+
+	case "y":
+		if m.selected >= 0 && m.selected < len(m.messages) {
+			if err := clipboard.WriteAll(m.messages[m.selected].Content); err != nil {
+				m.status = fmt.Sprintf("copy failed: %v", err)
+			} else {
+				m.status = "Copied to clipboard"
 			}
-			fmt.Print(token)
-		})
+		}
+		return m, nil
 
-		if firstToken {
-			// No tokens received, clear spinner
-			fmt.Print("\r\033[K")
+	case "enter", "v":
+		if m.selected >= 0 && m.selected < len(m.messages) {
+			return m, m.openInEditorCmd(m.messages[m.selected])
 		}
+		return m, nil
 
-		if err != nil {
-			fmt.Println()
-			fmt.Println(PrintError(err.Error()))
-		} else {
-			// Fetch final thread state to get the complete conversation
-			thread, err := client.GetThread(ctx, threadID, "values")
-			if err == nil && thread.Values != nil {
-				// Thread values contain the full message history
-				_ = api.GetMessages(thread.Values)
-			}
+	case "e":
+		if m.selected >= 0 && m.selected < len(m.messages) && m.messages[m.selected].Role == "user" {
+			return m, m.openComposeEditorCmd(m.messages[m.selected].Content, m.selected)
 		}
-		fmt.Println()
-		fmt.Println()
+		m.status = "can only edit a user message"
+		return m, nil
 	}
+	return m, nil
 }
 
-// Available slash commands
-var slashCommands = []struct {
-	name string
-	desc string
-}{
-	{"/configure", "Update connection settings"},
-	{"/quit", "Exit the chat"},
-	{"/exit", "Exit the chat"},
+// selectMessage moves the selection to idx (clamped to the valid range) and
+// scrolls the viewport so the newly selected message is visible.
+func (m *Model) selectMessage(idx int) {
+	if len(m.messages) == 0 {
+		m.selected = -1
+		return
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(m.messages) {
+		idx = len(m.messages) - 1
+	}
+	m.selected = idx
+	m.refreshViewport()
+	if idx < len(m.messageOffsets) {
+		offset := m.messageOffsets[idx]
+		if offset < m.viewport.YOffset {
+			m.viewport.SetYOffset(offset)
+		} else if offset >= m.viewport.YOffset+m.viewport.Height {
+			m.viewport.SetYOffset(offset - m.viewport.Height + 1)
+		}
+	}
 }
 
-// inputModel is a simple model just for getting user input
-type inputModel struct {
-	textarea      textarea.Model
-	value         string
-	quitting      bool
-	ctrlCPressed  bool
-	completions   []int // indices into slashCommands
-	completionIdx int   // selected completion
-	showComplete  bool  // whether to show completion menu
-}
+// openInEditorCmd suspends the program and opens msg's raw content in
+// $EDITOR (falling back to vi) for viewing.
+func (m *Model) openInEditorCmd(msg api.Message) tea.Cmd {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
 
-// ctrlCResetMsg is sent to reset the ctrl+c state after a timeout
-type ctrlCResetMsg struct{}
+	f, err := os.CreateTemp("", "lsc-message-*.txt")
+	if err != nil {
+		m.status = fmt.Sprintf("could not open $EDITOR: %v", err)
+		return nil
+	}
+	path := f.Name()
+	_, werr := f.WriteString(msg.Content)
+	f.Close()
+	if werr != nil {
+		os.Remove(path)
+		m.status = fmt.Sprintf("could not open $EDITOR: %v", werr)
+		return nil
+	}
 
-func (m inputModel) Init() tea.Cmd {
-	return textarea.Blink
+	cmd := exec.Command(editor, path)
+	m.status = "Viewing in " + editor + "..."
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		os.Remove(path)
+		return editorDoneMsg{err: err}
+	})
 }
 
-// getCompletions returns matching command indices for current input
-func (m *inputModel) getCompletions() []int {
-	text := m.textarea.Value()
-	if !strings.HasPrefix(text, "/") || strings.Contains(text, "\n") {
+// openComposeEditorCmd suspends the program and opens seed in $EDITOR
+// (falling back to vi) for composing or editing text. If branchFrom is -1,
+// the edited content is loaded back into the textarea (Ctrl+E, "/edit"); if
+// it's a message index, the edited content is resubmitted as a forked turn
+// from that message (editing a previously sent user message).
+func (m *Model) openComposeEditorCmd(seed string, branchFrom int) tea.Cmd {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	f, err := os.CreateTemp("", "lsc-compose-*.md")
+	if err != nil {
+		m.status = fmt.Sprintf("could not open $EDITOR: %v", err)
+		return nil
+	}
+	path := f.Name()
+	_, werr := f.WriteString(seed)
+	f.Close()
+	if werr != nil {
+		os.Remove(path)
+		m.status = fmt.Sprintf("could not open $EDITOR: %v", werr)
 		return nil
 	}
 
-	var matches []int
-	for i, cmd := range slashCommands {
-		if strings.HasPrefix(cmd.name, text) {
-			matches = append(matches, i)
+	cmd := exec.Command(editor, path)
+	m.status = "Editing in " + editor + "..."
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return editorInputMsg{err: err, branchFrom: branchFrom}
 		}
+		data, rerr := os.ReadFile(path)
+		if rerr != nil {
+			return editorInputMsg{err: rerr, branchFrom: branchFrom}
+		}
+		return editorInputMsg{content: strings.TrimRight(string(data), "\n"), branchFrom: branchFrom}
+	})
+}
+
+// branchFromEdit truncates the transcript and server-side thread state to
+// just before messages[idx] (a user message) and, once that truncation
+// completes, resubmits content as the next turn - forking the conversation
+// from the edited point.
+func (m Model) branchFromEdit(idx int, content string) (tea.Model, tea.Cmd) {
+	if idx < 0 || idx >= len(m.messages) || m.messages[idx].Role != "user" {
+		m.status = "can only edit a user message"
+		return m, nil
+	}
+
+	m.messages = append([]api.Message(nil), m.messages[:idx]...)
+	m.highlightCache = nil
+	m.selected = -1
+	m.focus = focusInput
+	m.refreshViewport()
+
+	history := append([]api.Message(nil), m.messages...)
+	m.sess.History = history
+	rawMessages := make([]interface{}, len(history))
+	for i, h := range history {
+		rawMessages[i] = map[string]interface{}{"role": h.Role, "content": h.Content}
+	}
+
+	client := m.sess.Client
+	threadID := m.sess.ThreadID
+	return m, func() tea.Msg {
+		_, err := client.UpdateThreadState(context.Background(), threadID, map[string]interface{}{"messages": rawMessages})
+		return branchTruncatedMsg{err: err, userMessage: content}
 	}
-	return matches
 }
 
-func (m inputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case ctrlCResetMsg:
-		m.ctrlCPressed = false
+// ctrlCResetMsg is sent to reset the ctrl+c state after a timeout
+type ctrlCResetMsg struct{}
+
+// submit handles Enter from StateInput: quitting, /configure, slash
+// commands, or starting a new streamed turn.
+func (m Model) submit() (tea.Model, tea.Cmd) {
+	input := strings.TrimSpace(m.textarea.Value())
+	if input == "" {
 		return m, nil
+	}
 
-	case tea.KeyMsg:
-		// Any key other than Ctrl+C resets the exit state
-		if msg.Type != tea.KeyCtrlC {
-			m.ctrlCPressed = false
+	if input == "/quit" || input == "/exit" {
+		m.quitting = true
+		return m, tea.Quit
+	}
+	if input == "/configure" {
+		m.quitting = true
+		m.err = fmt.Errorf("CONFIGURE")
+		return m, tea.Quit
+	}
+
+	m.textarea.Reset()
+	m.updateHeight()
+	m.showComplete = false
+	m.completions = nil
+
+	if strings.HasPrefix(input, "/") {
+		name, args := splitCommand(input)
+		cmd, ok := commands.Lookup(name)
+		if !ok {
+			m.appendMessage(api.Message{Role: "error", Content: fmt.Sprintf("unknown command: %s (try /help)", name)})
+			m.refreshViewport()
+			return m, nil
 		}
+		return m, cmd.Run(context.Background(), m.sess, args)
+	}
 
-		// Handle completion navigation
-		if m.showComplete && len(m.completions) > 0 {
-			switch msg.Type {
-			case tea.KeyTab, tea.KeyDown:
-				m.completionIdx = (m.completionIdx + 1) % len(m.completions)
-				return m, nil
-			case tea.KeyShiftTab, tea.KeyUp:
-				m.completionIdx = (m.completionIdx - 1 + len(m.completions)) % len(m.completions)
-				return m, nil
-			case tea.KeyEnter:
-				// Select completion
-				cmd := slashCommands[m.completions[m.completionIdx]]
-				m.textarea.Reset()
-				m.textarea.InsertString(cmd.name)
-				m.showComplete = false
-				m.completions = nil
-				return m, nil
-			case tea.KeyEsc:
-				m.showComplete = false
-				m.completions = nil
-				return m, nil
-			}
+	return m, m.beginTurn(input)
+}
+
+// beginTurn appends userMessage to the transcript and starts streaming the
+// assistant's reply. It's shared by submit (a normal Enter in StateInput)
+// and branchFromEdit's resubmit-after-truncate (editing a prior message).
+func (m *Model) beginTurn(userMessage string) tea.Cmd {
+	m.appendMessage(api.Message{Role: "user", Content: userMessage})
+	m.refreshViewport()
+	m.state = StateWaiting
+	m.currentResponse.Reset()
+	m.toolCalls = map[string]api.ToolCall{}
+	m.toolCallOrder = nil
+
+	return tea.Batch(m.spinner.Tick, m.startStream(userMessage))
+}
+
+// startStream launches StreamRun in a goroutine that feeds tokens and tool
+// calls into the model's channels, and returns the tea.Cmd that drains them.
+// The run's context is cancellable via m.streamCancel, so Ctrl+C can stop a
+// turn in progress (see cancelStream).
+func (m *Model) startStream(userMessage string) tea.Cmd {
+	m.replyChan = make(chan string)
+	m.toolCallChan = make(chan api.ToolCall)
+	m.replyDoneChan = make(chan error, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.streamCancel = cancel
+
+	replyChan := m.replyChan
+	toolCallChan := m.toolCallChan
+	doneChan := m.replyDoneChan
+	sess := m.sess
+	opts := runOptions(sess)
+
+	go func() {
+		err := sess.Client.StreamRun(ctx, sess.ThreadID, sess.AssistantID, userMessage,
+			func(token string) { replyChan <- token },
+			func(call api.ToolCall) { toolCallChan <- call },
+			opts...,
+		)
+		doneChan <- err
+	}()
+
+	return waitForStream(replyChan, toolCallChan, doneChan)
+}
+
+// runOptions resolves the active agent's RAG files and tool allow-list (if
+// any) into the RequestOptions StreamRun needs to attach them to the run.
+func runOptions(sess *commands.Session) []api.RequestOption {
+	var opts []api.RequestOption
+	if len(sess.RAGFiles) > 0 {
+		if ctx := loadRAGContext(sess.RAGFiles); ctx != "" {
+			opts = append(opts, api.WithRAGContext(ctx))
 		}
+	}
+	if len(sess.Tools) > 0 {
+		opts = append(opts, api.WithToolAllowList(sess.Tools))
+	}
+	return opts
+}
 
-		switch msg.Type {
-		case tea.KeyCtrlC:
-			if m.ctrlCPressed {
-				// Second Ctrl+C - actually quit
-				m.quitting = true
-				return m, tea.Quit
-			}
-			// First Ctrl+C - show warning
-			m.ctrlCPressed = true
-			return m, tea.Tick(time.Second, func(t time.Time) tea.Msg {
-				return ctrlCResetMsg{}
-			})
-		case tea.KeyCtrlD:
-			m.quitting = true
-			return m, tea.Quit
-		case tea.KeyCtrlJ:
-			// Ctrl+J inserts newline (ASCII line feed)
-			m.textarea.InsertString("\n")
-			m.updateHeight()
-			return m, nil
-		case tea.KeyEnter:
-			// Enter submits
-			m.value = strings.TrimSpace(m.textarea.Value())
-			if m.value == "" {
-				return m, nil
-			}
-			if m.value == "/quit" || m.value == "/exit" {
-				m.quitting = true
-			}
-			return m, tea.Quit
-		case tea.KeyTab:
-			// Tab triggers completion if typing a command
-			completions := m.getCompletions()
-			if len(completions) > 0 {
-				m.completions = completions
-				m.completionIdx = 0
-				m.showComplete = true
-				return m, nil
+// loadRAGContext expands each pattern (a literal path or glob) and
+// concatenates the matched files' contents, labeled by path, to attach to a
+// run as retrieval context. Patterns that fail to glob or match nothing are
+// tried as literal paths; files that still can't be read are skipped rather
+// than failing the turn.
+func loadRAGContext(patterns []string) string {
+	var sb strings.Builder
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil || len(matches) == 0 {
+			matches = []string{pattern}
+		}
+		for _, path := range matches {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
 			}
+			fmt.Fprintf(&sb, "--- %s ---\n%s\n", path, data)
 		}
 	}
+	return sb.String()
+}
 
-	var cmd tea.Cmd
-	m.textarea, cmd = m.textarea.Update(msg)
-	m.updateHeight()
+// cancelStream is invoked when Ctrl+C is pressed while a turn is in flight
+// (StateWaiting or StateStreaming). It cancels the run's context, flushes
+// whatever partial response has arrived into the transcript, and returns the
+// UI to StateInput without exiting. The stream's goroutine is left to unwind
+// on its own; it must keep being drained (replyChan/toolCallChan are
+// unbuffered, so a goroutine blocked mid-send would otherwise leak forever),
+// so StreamDoneMsg's m.stopped guard discards whatever values arrive after it.
+func (m *Model) cancelStream() tea.Cmd {
+	if m.streamCancel != nil {
+		m.streamCancel()
+	}
+	m.stopped = true
+	m.finalizeTurn()
+	m.appendMessage(api.Message{Role: "system", Content: "⏹ stopped"})
+	m.state = StateInput
+	m.refreshViewport()
+	return tea.Batch(m.waitForStream(), textarea.Blink)
+}
 
-	// Update completions as user types
-	if strings.HasPrefix(m.textarea.Value(), "/") {
-		m.completions = m.getCompletions()
-		m.showComplete = len(m.completions) > 0
-		if m.completionIdx >= len(m.completions) {
-			m.completionIdx = 0
+// waitForStream returns a tea.Cmd reads exactly one value from a stream's
+// channels, translating it into the corresponding Bubble Tea message.
+// Update re-invokes this (via Model.waitForStream) after each TokenMsg/
+// ToolCallMsg to keep draining until StreamDoneMsg arrives.
+func waitForStream(replyChan chan string, toolCallChan chan api.ToolCall, doneChan chan error) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case token := <-replyChan:
+			return TokenMsg{Token: token}
+		case call := <-toolCallChan:
+			return ToolCallMsg{Call: call}
+		case err := <-doneChan:
+			return StreamDoneMsg{Err: err}
 		}
-	} else {
-		m.showComplete = false
-		m.completions = nil
 	}
+}
 
-	return m, cmd
+func (m *Model) waitForStream() tea.Cmd {
+	return waitForStream(m.replyChan, m.toolCallChan, m.replyDoneChan)
+}
+
+// resumeCmd submits a y/n decision for the pending interrupt.
+func (m *Model) resumeCmd(approve bool) tea.Cmd {
+	ie := m.pendingInterrupt
+	client := m.sess.Client
+	return func() tea.Msg {
+		err := client.ResumeRun(context.Background(), ie.ThreadID, ie.Info.RunID, approve)
+		return resumeDoneMsg{err: err}
+	}
+}
+
+// refreshHistoryCmd re-fetches the thread's full state after a turn
+// completes, so that server-side state changes are reflected in sess.History.
+func (m *Model) refreshHistoryCmd() tea.Cmd {
+	client := m.sess.Client
+	threadID := m.sess.ThreadID
+	return func() tea.Msg {
+		thread, err := client.GetThread(context.Background(), threadID, "values")
+		if err != nil || thread.Values == nil {
+			return nil
+		}
+		return historyMsg{history: api.GetMessages(thread.Values)}
+	}
+}
+
+// finalizeTurn commits the in-flight tool calls and assistant response into
+// the permanent transcript.
+func (m *Model) finalizeTurn() {
+	for _, id := range m.toolCallOrder {
+		m.appendMessage(api.Message{Role: "tool", Content: ToolCallText(m.toolCalls[id])})
+	}
+	if m.currentResponse.Len() > 0 {
+		m.appendMessage(api.Message{Role: "assistant", Content: m.currentResponse.String()})
+	}
+	m.toolCalls = nil
+	m.toolCallOrder = nil
+	m.currentResponse.Reset()
+}
+
+// liveTurn renders the in-flight tool calls and assistant response that
+// haven't been committed to m.messages yet.
+func (m *Model) liveTurn() string {
+	var sb strings.Builder
+	for _, id := range m.toolCallOrder {
+		sb.WriteString(FormatToolCall(m.toolCalls[id]))
+		sb.WriteString("\n")
+	}
+	if m.currentResponse.Len() > 0 {
+		sb.WriteString(AssistantLabel)
+		sb.WriteString(RenderMarkdown(m.currentResponse.String(), m.width))
+	}
+	return sb.String()
+}
+
+// renderTranscript renders m.messages (highlighting the selected one when
+// focusMessages is active) and recomputes messageOffsets, the line offset of
+// each message in the rendered output. It's recomputed on every render
+// rather than cached across resizes, since word-wrapping inside the
+// viewport can shift line counts as width changes.
+func (m *Model) renderTranscript() string {
+	if len(m.highlightCache) < len(m.messages) {
+		grown := make([]string, len(m.messages))
+		copy(grown, m.highlightCache)
+		m.highlightCache = grown
+	}
+
+	var sb strings.Builder
+	m.messageOffsets = make([]int, len(m.messages))
+	line := 0
+	for i, msg := range m.messages {
+		m.messageOffsets[i] = line
+		if m.highlightCache[i] == "" {
+			m.highlightCache[i] = formatViewMessage(msg, m.width)
+		}
+		rendered := m.highlightCache[i]
+		if m.focus == focusMessages && i == m.selected {
+			rendered = SelectedMessageStyle.Render(rendered)
+		}
+		sb.WriteString(rendered)
+		if i < len(m.messages)-1 {
+			sb.WriteString("\n\n")
+		}
+		line += strings.Count(rendered, "\n") + 2
+	}
+	return sb.String()
 }
 
-// updateHeight adjusts textarea height based on content
-func (m *inputModel) updateHeight() {
+// invalidateHighlightCache discards every cached rendering of messages, so
+// the next renderTranscript call re-runs chroma with the current theme.
+func (m *Model) invalidateHighlightCache() {
+	m.highlightCache = nil
+}
+
+// refreshViewport rebuilds the viewport's content from the finalized
+// transcript plus whatever is currently streaming, and scrolls to bottom
+// unless the transcript is focused (where scrolling is driven by selection).
+func (m *Model) refreshViewport() {
+	if !m.ready {
+		return
+	}
+	content := m.renderTranscript()
+	if live := m.liveTurn(); live != "" {
+		if content != "" {
+			content += "\n\n"
+		}
+		content += live
+	}
+	atBottom := m.viewport.AtBottom()
+	m.viewport.SetContent(content)
+	if m.focus != focusMessages && atBottom {
+		m.viewport.GotoBottom()
+	}
+}
+
+// getCompletions returns matching command indices for current input
+func (m *Model) getCompletions() []int {
+	text := m.textarea.Value()
+	if !strings.HasPrefix(text, "/") || strings.Contains(text, "\n") {
+		return nil
+	}
+
+	var matches []int
+	for i, cmd := range slashCommands {
+		if strings.HasPrefix(cmd.name, text) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// updateHeight adjusts the textarea height based on its content.
+func (m *Model) updateHeight() {
 	content := m.textarea.Value()
 	lines := strings.Count(content, "\n") + 1
-	// Clamp between 1 and 10 lines
 	if lines < 1 {
 		lines = 1
 	}
@@ -482,24 +1024,102 @@ func (m *inputModel) updateHeight() {
 	m.textarea.SetHeight(lines)
 }
 
-func (m inputModel) View() string {
-	view := m.textarea.View()
+// View renders the UI
+func (m Model) View() string {
+	if m.quitting {
+		if m.err != nil && (m.err.Error() == "CONFIGURE" || m.err.Error() == "THREADLIST") {
+			return ""
+		}
+		return "Goodbye!\n"
+	}
+	if !m.ready {
+		return "Initializing...\n"
+	}
+
+	var footer strings.Builder
+	if m.focus == focusMessages {
+		footer.WriteString(SystemStyle.Render("MESSAGES  j/k move  g/G top/bottom  ^U/^D page  y copy  enter view  e edit&resubmit  tab/esc back"))
+		if m.status != "" {
+			footer.WriteString("\n" + SystemStyle.Render(m.status))
+		}
+		return m.viewport.View() + "\n" + footer.String()
+	}
 
-	// Show completion menu
-	if m.showComplete && len(m.completions) > 0 {
-		view += "\n"
-		for i, idx := range m.completions {
-			cmd := slashCommands[idx]
-			if i == m.completionIdx {
-				view += PromptStyle.Render("→ "+cmd.name) + " " + SystemStyle.Render(cmd.desc) + "\n"
-			} else {
-				view += SystemStyle.Render("  "+cmd.name+" "+cmd.desc) + "\n"
+	switch m.state {
+	case StateInput:
+		footer.WriteString(m.textarea.View())
+		if m.showComplete && len(m.completions) > 0 {
+			footer.WriteString("\n")
+			for i, idx := range m.completions {
+				cmd := slashCommands[idx]
+				if i == m.completionIdx {
+					footer.WriteString(PromptStyle.Render("→ "+cmd.name) + " " + SystemStyle.Render(cmd.desc) + "\n")
+				} else {
+					footer.WriteString(SystemStyle.Render("  "+cmd.name+" "+cmd.desc) + "\n")
+				}
 			}
 		}
+		if m.ctrlCPressed {
+			footer.WriteString("\n" + SystemStyle.Render("Press Ctrl+C again to exit"))
+		}
+	case StateWaiting:
+		footer.WriteString(m.spinner.View() + " Thinking...")
+	case StateStreaming:
+		footer.WriteString(SystemStyle.Render("Press Ctrl+C to stop"))
+	case StateConfirm:
+		footer.WriteString(PromptStyle.Render(fmt.Sprintf("Approve tool call? %v [y/N]", m.pendingInterrupt.Info.Value)))
+	}
+
+	return m.viewport.View() + "\n" + footer.String()
+}
+
+// RunChat runs the interactive chat as a single persistent Bubble Tea
+// program for the life of the thread: tokens and tool calls stream into the
+// same viewport-backed transcript that /configure, slash commands, and
+// terminal resizing all operate against, instead of rebooting a tea.Program
+// for every turn.
+func RunChat(client *api.Client, assistantID, threadID string, history []api.Message, systemPrompt string, agents []config.Agent, ragFiles, tools []string) error {
+	p := tea.NewProgram(NewModel(client, assistantID, threadID, history, systemPrompt, agents, ragFiles, tools))
+	finalModel, err := p.Run()
+	if err != nil {
+		return err
 	}
 
-	if m.ctrlCPressed {
-		view += "\n" + SystemStyle.Render("Press Ctrl+C again to exit")
+	m, ok := finalModel.(Model)
+	if ok && m.err != nil {
+		return m.err
 	}
-	return view
+	return nil
+}
+
+// splitCommand splits a slash-command line into its name and the remaining
+// argument text, e.g. "/switch abc123" -> ("/switch", "abc123").
+func splitCommand(input string) (name, args string) {
+	fields := strings.SplitN(input, " ", 2)
+	name = fields[0]
+	if len(fields) > 1 {
+		args = strings.TrimSpace(fields[1])
+	}
+	return name, args
+}
+
+// Available slash commands, shown by tab-completion.
+var slashCommands = []struct {
+	name string
+	desc string
+}{
+	{"/configure", "Update connection settings"},
+	{"/quit", "Exit the chat"},
+	{"/exit", "Exit the chat"},
+	{"/help", "List available slash commands"},
+	{"/threads", "Browse, switch, rename, or delete threads"},
+	{"/switch", "Switch to another thread"},
+	{"/fork", "Fork the current thread into a new one"},
+	{"/rewind", "Drop the last n exchanges"},
+	{"/system", "Override the system prompt"},
+	{"/agent", "Switch agents, or list them"},
+	{"/export", "Export the conversation to a file"},
+	{"/save", "Save the current thread under a name"},
+	{"/theme", "Switch the code-block color theme"},
+	{"/edit", "Compose a long message in $EDITOR"},
 }
@@ -35,6 +35,15 @@ var (
 	SpinnerStyle = lipgloss.NewStyle().
 			Foreground(PromptColor)
 
+	ToolCallStyle = lipgloss.NewStyle().
+			Foreground(SystemColor).
+			Italic(true)
+
+	// SelectedMessageStyle highlights the message under the cursor when the
+	// viewport is focused (focusMessages).
+	SelectedMessageStyle = lipgloss.NewStyle().
+				Reverse(true)
+
 	// Logo styles
 	logoAccent = lipgloss.NewStyle().Foreground(LogoAccent)
 	logoBody   = lipgloss.NewStyle().Foreground(LogoBody)
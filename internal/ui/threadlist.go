@@ -0,0 +1,343 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/wfh/lsc/internal/api"
+)
+
+// threadListPageSize is how many threads SearchThreads fetches per page as
+// the user scrolls toward the bottom of what's loaded so far.
+const threadListPageSize = 20
+
+// threadListMode selects what ThreadListModel's keys and View do.
+type threadListMode int
+
+const (
+	// threadListBrowsing is the default: move the cursor, open/rename/delete.
+	threadListBrowsing threadListMode = iota
+	// threadListConfirmDelete asks y/n before deleting the thread under the cursor.
+	threadListConfirmDelete
+	// threadListRenaming edits a title for the thread under the cursor.
+	threadListRenaming
+)
+
+// threadsLoadedMsg carries the next page of threads fetched by SearchThreads.
+type threadsLoadedMsg struct {
+	threads []api.Thread
+	err     error
+}
+
+// threadDeletedMsg is sent when DeleteThread for the cursor's thread completes.
+type threadDeletedMsg struct {
+	threadID string
+	err      error
+}
+
+// threadRenamedMsg is sent when UpdateThreadMetadata for the cursor's thread completes.
+type threadRenamedMsg struct {
+	thread *api.Thread
+	err    error
+}
+
+// ThreadListModel is the full-screen conversation list: thread ID, created
+// time, and first-user-message preview, with keybindings to open (Enter),
+// delete (d, then y/n), and rename (r, storing a title in thread metadata).
+// It pages in more threads via SearchThreads as the cursor nears the bottom
+// of what's loaded.
+type ThreadListModel struct {
+	client *api.Client
+
+	threads   []api.Thread
+	cursor    int
+	exhausted bool // true once SearchThreads returns fewer than a full page
+
+	mode    threadListMode
+	rename  textinput.Model
+	status  string
+	loading bool
+
+	selected *api.Thread
+	quitting bool
+}
+
+// NewThreadListModel creates a thread list seeded with the first page of
+// results already fetched.
+func NewThreadListModel(client *api.Client, firstPage []api.Thread) ThreadListModel {
+	ti := textinput.New()
+	ti.Placeholder = "New title"
+	ti.CharLimit = 200
+
+	return ThreadListModel{
+		client:    client,
+		threads:   firstPage,
+		rename:    ti,
+		exhausted: len(firstPage) < threadListPageSize,
+	}
+}
+
+// Init initializes the thread list
+func (m ThreadListModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (m ThreadListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case threadsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = fmt.Sprintf("failed to load more threads: %v", msg.err)
+			m.exhausted = true
+			return m, nil
+		}
+		m.threads = append(m.threads, msg.threads...)
+		m.exhausted = len(msg.threads) < threadListPageSize
+		return m, nil
+
+	case threadDeletedMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("delete failed: %v", msg.err)
+			return m, nil
+		}
+		for i, t := range m.threads {
+			if t.ThreadID == msg.threadID {
+				m.threads = append(m.threads[:i], m.threads[i+1:]...)
+				break
+			}
+		}
+		if m.cursor >= len(m.threads) {
+			m.cursor = len(m.threads) - 1
+		}
+		m.status = "Deleted"
+		return m, nil
+
+	case threadRenamedMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("rename failed: %v", msg.err)
+			return m, nil
+		}
+		for i, t := range m.threads {
+			if t.ThreadID == msg.thread.ThreadID {
+				m.threads[i] = *msg.thread
+				break
+			}
+		}
+		m.status = "Renamed"
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.mode {
+		case threadListConfirmDelete:
+			return m.handleConfirmDeleteKey(msg)
+		case threadListRenaming:
+			return m.handleRenameKey(msg)
+		default:
+			return m.handleBrowsingKey(msg)
+		}
+	}
+
+	return m, nil
+}
+
+func (m ThreadListModel) handleBrowsingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.quitting = true
+		return m, tea.Quit
+
+	case tea.KeyUp, tea.KeyShiftTab:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, nil
+
+	case tea.KeyDown, tea.KeyTab:
+		if m.cursor < len(m.threads)-1 {
+			m.cursor++
+		}
+		return m, m.maybeLoadMoreCmd()
+
+	case tea.KeyEnter:
+		if len(m.threads) > 0 {
+			m.selected = &m.threads[m.cursor]
+		}
+		return m, tea.Quit
+	}
+
+	switch msg.String() {
+	case "d":
+		if len(m.threads) > 0 {
+			m.mode = threadListConfirmDelete
+			m.status = ""
+		}
+		return m, nil
+
+	case "r":
+		if len(m.threads) > 0 {
+			m.mode = threadListRenaming
+			m.rename.SetValue(threadTitle(m.threads[m.cursor]))
+			m.rename.Focus()
+			m.status = ""
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m ThreadListModel) handleConfirmDeleteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		m.mode = threadListBrowsing
+		threadID := m.threads[m.cursor].ThreadID
+		client := m.client
+		return m, func() tea.Msg {
+			err := client.DeleteThread(context.Background(), threadID)
+			return threadDeletedMsg{threadID: threadID, err: err}
+		}
+	case "n", "N", "esc":
+		m.mode = threadListBrowsing
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m ThreadListModel) handleRenameKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.mode = threadListBrowsing
+		m.rename.Blur()
+		return m, nil
+
+	case tea.KeyEnter:
+		m.mode = threadListBrowsing
+		m.rename.Blur()
+		title := m.rename.Value()
+		threadID := m.threads[m.cursor].ThreadID
+		client := m.client
+		return m, func() tea.Msg {
+			thread, err := client.UpdateThreadMetadata(context.Background(), threadID, map[string]interface{}{"title": title})
+			return threadRenamedMsg{thread: thread, err: err}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.rename, cmd = m.rename.Update(msg)
+	return m, cmd
+}
+
+// maybeLoadMoreCmd fetches the next page once the cursor is within a few
+// rows of the end of what's loaded, unless a prior page already came back
+// short (exhausted) or a fetch is already in flight.
+func (m *ThreadListModel) maybeLoadMoreCmd() tea.Cmd {
+	if m.exhausted || m.loading {
+		return nil
+	}
+	if m.cursor < len(m.threads)-3 {
+		return nil
+	}
+	m.loading = true
+	client := m.client
+	offset := len(m.threads)
+	return func() tea.Msg {
+		threads, err := client.SearchThreads(context.Background(), threadListPageSize, offset)
+		return threadsLoadedMsg{threads: threads, err: err}
+	}
+}
+
+// threadTitle returns the thread's stored title (set by a prior rename), or
+// the empty string if it has none.
+func threadTitle(thread api.Thread) string {
+	if thread.Metadata == nil {
+		return ""
+	}
+	title, _ := thread.Metadata["title"].(string)
+	return title
+}
+
+// View renders the thread list
+func (m ThreadListModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	s := "Conversations:\n\n"
+
+	for i, thread := range m.threads {
+		threadID := thread.ThreadID
+		if len(threadID) > 8 {
+			threadID = threadID[:8]
+		}
+
+		label := threadTitle(thread)
+		if label == "" {
+			label = GetThreadPreview(thread)
+		}
+
+		line := fmt.Sprintf("%-8s  %-19s  %s", threadID, thread.CreatedAt, label)
+
+		if i == m.cursor {
+			s += selectedStyle.Render("> " + line)
+		} else {
+			s += unselectedStyle.Render("  " + line)
+		}
+		s += "\n"
+	}
+	if len(m.threads) == 0 {
+		s += unselectedStyle.Render("  (no threads found)") + "\n"
+	}
+
+	switch m.mode {
+	case threadListConfirmDelete:
+		s += "\n" + PromptStyle.Render(fmt.Sprintf("Delete thread %s? [y/N]", m.threads[m.cursor].ThreadID))
+	case threadListRenaming:
+		s += "\n" + PromptStyle.Render("New title: ") + m.rename.View()
+	default:
+		s += "\n" + unselectedStyle.Render("(↑/↓ move, enter open, r rename, d delete, esc cancel)")
+		if m.status != "" {
+			s += "\n" + SystemStyle.Render(m.status)
+		}
+	}
+
+	return s
+}
+
+// Selected returns the thread chosen with Enter, if any.
+func (m ThreadListModel) Selected() *api.Thread {
+	return m.selected
+}
+
+// IsQuitting returns true if the user cancelled without selecting a thread.
+func (m ThreadListModel) IsQuitting() bool {
+	return m.quitting
+}
+
+// RunThreadList fetches the first page of threads and runs the full-screen
+// conversation list, returning the thread the user opened with Enter (nil if
+// they cancelled).
+func RunThreadList(client *api.Client) (*api.Thread, error) {
+	threads, err := client.SearchThreads(context.Background(), threadListPageSize, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list threads: %w", err)
+	}
+
+	model := NewThreadListModel(client, threads)
+	p := tea.NewProgram(model)
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	m := finalModel.(ThreadListModel)
+	if m.IsQuitting() {
+		return nil, nil
+	}
+
+	return m.Selected(), nil
+}
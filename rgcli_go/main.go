@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/charmbracelet/huh"
 
@@ -15,7 +16,12 @@ import (
 
 func main() {
 	resume := flag.Bool("resume", false, "Resume an existing thread")
+	list := flag.Bool("list", false, "Open the conversation list view to pick a thread")
 	showVersion := flag.Bool("version", false, "Show version")
+	retryLast := flag.Bool("retry-last", false, "Replay the last run using its stored idempotency key instead of creating a new one")
+	var agentName string
+	flag.StringVar(&agentName, "a", "", "Agent to use (see config agents, or /agent to switch mid-session)")
+	flag.StringVar(&agentName, "agent", "", "Agent to use (see config agents, or /agent to switch mid-session)")
 	flag.Parse()
 
 	if *showVersion {
@@ -23,7 +29,15 @@ func main() {
 		return
 	}
 
-	if err := run(*resume); err != nil {
+	if *retryLast {
+		if err := runRetryLast(); err != nil {
+			fmt.Fprintln(os.Stderr, ui.PrintError(err.Error()))
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := run(*resume, *list, agentName); err != nil {
 		fmt.Fprintln(os.Stderr, ui.PrintError(err.Error()))
 		os.Exit(1)
 	}
@@ -32,7 +46,32 @@ func main() {
 // version is set by goreleaser ldflags
 var version = "dev"
 
-func run(resume bool) error {
+// runRetryLast replays the last stream_run call recorded in
+// ~/.lsc/idempotency.json, reusing its idempotency key and thread so the
+// server dedups it instead of starting a duplicate run.
+func runRetryLast() error {
+	rec, ok, err := api.LastRun()
+	if err != nil {
+		return fmt.Errorf("failed to read idempotency store: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("no previous run to retry")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	client := api.NewClient(cfg)
+
+	fmt.Printf("Retrying run on thread %s with idempotency key %s...\n", rec.ThreadID, rec.Key)
+	ctx := context.Background()
+	return client.StreamRun(ctx, rec.ThreadID, rec.AssistantID, rec.UserMessage, func(token string) {
+		fmt.Print(token)
+	}, nil, api.WithIdempotencyKey(rec.Key))
+}
+
+func run(resume, list bool, agentName string) error {
 	// Check if config exists
 	if !config.Exists() {
 		fmt.Println("Welcome to lsc! Let's configure your connection.")
@@ -57,6 +96,14 @@ func run(resume bool) error {
 	// Create API client
 	client := api.NewClient(cfg)
 
+	if cfg.Theme != "" && !ui.SetTheme(cfg.Theme) {
+		fmt.Printf("Unknown theme %q in config, using default.\n", cfg.Theme)
+	}
+
+	// Resolve the agent to use: -a/--agent, falling back to the config's
+	// default_agent, falling back to the bare assistant_id.
+	assistantID, systemPrompt, ragFiles, tools := resolveAgent(cfg, agentName)
+
 	// Handle resume flow
 	var threadID string
 	var history []api.Message
@@ -72,6 +119,17 @@ func run(resume bool) error {
 		}
 		threadID = thread.ThreadID
 		history = messages
+	} else if list {
+		thread, messages, err := handleList(client)
+		if err != nil {
+			return err
+		}
+		if thread == nil {
+			// User cancelled
+			return nil
+		}
+		threadID = thread.ThreadID
+		history = messages
 	} else {
 		// Create new thread
 		ctx := context.Background()
@@ -84,7 +142,7 @@ func run(resume bool) error {
 
 	// Run chat loop
 	for {
-		err := ui.RunChatLoop(client, cfg.AssistantID, threadID, history)
+		err := ui.RunChat(client, assistantID, threadID, history, systemPrompt, cfg.Agents, ragFiles, tools)
 		if err != nil && err.Error() == "CONFIGURE" {
 			// User wants to reconfigure
 			if err := runConfigure(); err != nil {
@@ -96,17 +154,45 @@ func run(resume bool) error {
 				return fmt.Errorf("failed to reload config: %w", err)
 			}
 			client = api.NewClient(cfg)
+			assistantID, systemPrompt, ragFiles, tools = resolveAgent(cfg, agentName)
 			history = nil // Clear history since we might have new settings
 			continue
 		}
+		if err != nil && err.Error() == "THREADLIST" {
+			// User wants to browse/switch/rename/delete threads
+			thread, messages, err := handleList(client)
+			if err != nil {
+				return err
+			}
+			if thread != nil {
+				threadID = thread.ThreadID
+				history = messages
+			}
+			continue
+		}
 		return err
 	}
 }
 
+// resolveAgent picks the assistant ID, system prompt override, RAG files,
+// and tool allow-list to use: the agent named by -a/--agent, else
+// cfg.DefaultAgent, else the bare cfg.AssistantID with no overrides.
+func resolveAgent(cfg *config.Config, agentName string) (assistantID, systemPrompt string, ragFiles, tools []string) {
+	name := agentName
+	if name == "" {
+		name = cfg.DefaultAgent
+	}
+	if agent, ok := cfg.FindAgent(name); ok {
+		return agent.AssistantID, agent.SystemPrompt, agent.RAGFiles, agent.Tools
+	}
+	return cfg.AssistantID, "", nil, nil
+}
+
 func runConfigure() error {
-	var endpoint, apiKey, assistantID string
+	var endpoint, apiKey, assistantID, defaultAgent string
 	var authType string
 	customHeaders := make(map[string]string)
+	var agents []config.Agent
 
 	// Load existing config if available
 	if config.Exists() {
@@ -116,6 +202,8 @@ func runConfigure() error {
 			apiKey = cfg.ApiKey
 			assistantID = cfg.AssistantID
 			customHeaders = cfg.CustomHeaders
+			agents = cfg.Agents
+			defaultAgent = cfg.DefaultAgent
 			// Determine auth type from existing config
 			if apiKey != "" {
 				authType = "apikey"
@@ -239,12 +327,99 @@ func runConfigure() error {
 		assistantID = "docs_agent"
 	}
 
+	// Step 5: Agents (optional named bundles of assistant ID + system
+	// prompt + RAG files + tool allow-list, selectable with -a/--agent)
+	addAgent := false
+	err = huh.NewConfirm().
+		Title("Add or edit an agent?").
+		Description("Agents bundle an assistant ID with a system prompt, RAG files, and allowed tools").
+		Value(&addAgent).
+		Run()
+	if err != nil {
+		return err
+	}
+	for addAgent {
+		var agent config.Agent
+		var ragFiles, tools string
+
+		err = huh.NewInput().
+			Title("Agent name").
+			Placeholder("docs-writer").
+			Value(&agent.Name).
+			Run()
+		if err != nil {
+			return err
+		}
+		if agent.Name == "" {
+			break
+		}
+
+		err = huh.NewInput().
+			Title("Assistant ID").
+			Value(&agent.AssistantID).
+			Run()
+		if err != nil {
+			return err
+		}
+
+		err = huh.NewText().
+			Title("System prompt override (optional)").
+			Value(&agent.SystemPrompt).
+			Run()
+		if err != nil {
+			return err
+		}
+
+		err = huh.NewInput().
+			Title("RAG files/globs (optional, comma-separated)").
+			Placeholder("docs/**/*.md, README.md").
+			Value(&ragFiles).
+			Run()
+		if err != nil {
+			return err
+		}
+		agent.RAGFiles = splitCommaList(ragFiles)
+
+		err = huh.NewInput().
+			Title("Allowed tools (optional, comma-separated)").
+			Placeholder("search, retrieve").
+			Value(&tools).
+			Run()
+		if err != nil {
+			return err
+		}
+		agent.Tools = splitCommaList(tools)
+
+		agents = upsertAgent(agents, agent)
+
+		err = huh.NewConfirm().
+			Title("Add another agent?").
+			Value(&addAgent).
+			Run()
+		if err != nil {
+			return err
+		}
+	}
+	if len(agents) > 0 {
+		err = huh.NewSelect[string]().
+			Title("Default agent").
+			Description("Used when -a/--agent isn't given").
+			Options(agentOptions(agents)...).
+			Value(&defaultAgent).
+			Run()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Save config
 	cfg := &config.Config{
 		Endpoint:      endpoint,
 		ApiKey:        apiKey,
 		AssistantID:   assistantID,
 		CustomHeaders: customHeaders,
+		Agents:        agents,
+		DefaultAgent:  defaultAgent,
 	}
 
 	if err := config.Save(cfg); err != nil {
@@ -257,12 +432,46 @@ func runConfigure() error {
 	return nil
 }
 
+// splitCommaList splits a comma-separated input field into trimmed,
+// non-empty entries, or returns nil for blank input.
+func splitCommaList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// upsertAgent replaces the agent with the same name as agent, or appends it
+// if no such agent exists yet.
+func upsertAgent(agents []config.Agent, agent config.Agent) []config.Agent {
+	for i, a := range agents {
+		if a.Name == agent.Name {
+			agents[i] = agent
+			return agents
+		}
+	}
+	return append(agents, agent)
+}
+
+// agentOptions builds the huh.Select options for choosing a default agent.
+func agentOptions(agents []config.Agent) []huh.Option[string] {
+	opts := make([]huh.Option[string], len(agents))
+	for i, a := range agents {
+		opts[i] = huh.NewOption(a.Name, a.Name)
+	}
+	return opts
+}
+
 func handleResume(client *api.Client) (*api.Thread, []api.Message, error) {
 	ctx := context.Background()
 
 	// Search for threads
 	fmt.Println("Searching for threads...")
-	threads, err := client.SearchThreads(ctx, 20)
+	threads, err := client.SearchThreads(ctx, 20, 0)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to search threads: %w", err)
 	}
@@ -296,3 +505,27 @@ func handleResume(client *api.Client) (*api.Thread, []api.Message, error) {
 	messages := api.GetMessages(state.Values)
 	return selected, messages, nil
 }
+
+// handleList opens the full-screen conversation list view and loads the
+// history for whichever thread the user opens with Enter. A nil *api.Thread
+// with a nil error means the user cancelled without picking one.
+func handleList(client *api.Client) (*api.Thread, []api.Message, error) {
+	ctx := context.Background()
+
+	selected, err := ui.RunThreadList(client)
+	if err != nil {
+		return nil, nil, err
+	}
+	if selected == nil {
+		return nil, nil, nil
+	}
+
+	state, err := client.GetThreadState(ctx, selected.ThreadID)
+	if err != nil {
+		// Non-fatal - just continue without history
+		return selected, nil, nil
+	}
+
+	messages := api.GetMessages(state.Values)
+	return selected, messages, nil
+}